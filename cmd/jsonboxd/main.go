@@ -0,0 +1,59 @@
+// Command jsonboxd serves the jsonbox REST API, storing the records in a data directory.
+//
+//	go run ./cmd/jsonboxd -addr :3000 -data ./data
+//
+// Then jsonboxgo.NewClient("http://localhost:3000", boxId) works offline.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/xshoji/jsonbox-go/internal/server"
+)
+
+func main() {
+	addr := flag.String("addr", ":3000", "Address to listen on")
+	dataDir := flag.String("data", "data", "Directory of the box logs")
+	maxRecordSize := flag.Int("max-record-size", server.DefaultMaxRecordSize, "Size limit of a record in bytes, 0 is no limit")
+	maxBoxSize := flag.Int("max-box-size", 0, "Size limit of all the records of a box in bytes, 0 is no limit")
+	flag.Parse()
+
+	handler, err := server.New(
+		server.WithDataDir(*dataDir),
+		server.WithMaxRecordSize(*maxRecordSize),
+		server.WithMaxBoxSize(*maxBoxSize),
+	)
+	if err != nil {
+		log.Fatal("Loading the data directory failed. | ", err)
+	}
+	httpServer := &http.Server{Addr: *addr, Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	shutdown := make(chan struct{})
+	go func() {
+		defer close(shutdown)
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = httpServer.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("jsonboxd listening on %s, data directory %s", *addr, *dataDir)
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal("ListenAndServe failed. | ", err)
+	}
+	// wait for the requests in flight before closing the logs
+	<-shutdown
+	if err := handler.Close(); err != nil {
+		log.Fatal("Closing the logs failed. | ", err)
+	}
+}
@@ -8,7 +8,6 @@ import (
 	"github.com/xshoji/jsonbox-go/jsonboxgo"
 	"log"
 	"math/rand"
-	"net/http"
 	"os"
 	"strconv"
 	"time"
@@ -30,7 +29,10 @@ func main() {
 		log.Fatal("Environment variable \"BOX_ID\" is not defined.")
 	}
 	collection := "users"
-	client := jsonboxgo.NewClient(baseUrl, boxId, http.DefaultClient)
+	client, err := jsonboxgo.NewClient(baseUrl, boxId, jsonboxgo.WithTimeout(10*time.Second))
+	if err != nil {
+		log.Fatal("NewClient failed. | ", err)
+	}
 
 	user := User{
 		Name:     "taro_" + randomString(),
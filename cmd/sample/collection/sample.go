@@ -0,0 +1,88 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/xshoji/jsonbox-go/jsonboxgo"
+	"log"
+	"math/rand"
+	"os"
+	"strconv"
+	"time"
+)
+
+const baseUrl = "https://jsonbox.io/"
+
+type User struct {
+	jsonboxgo.Meta
+	Name     string `json:"name,omitempty"`
+	Age      int    `json:"age,omitempty"`
+	Language string `json:"language,omitempty"`
+}
+
+func main() {
+	boxId := os.Getenv("BOX_ID")
+	if boxId == "" {
+		log.Fatal("Environment variable \"BOX_ID\" is not defined.")
+	}
+	client, err := jsonboxgo.NewClient(baseUrl, boxId, jsonboxgo.WithTimeout(10*time.Second))
+	if err != nil {
+		log.Fatal("NewClient failed. | ", err)
+	}
+	users := jsonboxgo.NewCollection[User](client, "users")
+
+	// Create
+	createdUser, err := users.Create(User{
+		Name:     "taro_" + randomString(),
+		Age:      randomNumber(),
+		Language: "JP",
+	})
+	if err != nil {
+		log.Fatal("Create failed. | ", err)
+	}
+	fmt.Println(">>> Create")
+	fmt.Printf("%+v\n", createdUser)
+	fmt.Println("")
+
+	// List
+	listedUsers, err := users.List(jsonboxgo.NewQueryBuilder().Limit(3))
+	if err != nil {
+		log.Fatal("List failed. | ", err)
+	}
+	fmt.Println(">>> List")
+	fmt.Printf("%+v\n", listedUsers)
+	fmt.Println("")
+
+	// Update
+	createdUser.Name = "updated_" + randomString()
+	updatedUser, err := users.Update(createdUser.Id, createdUser)
+	if err != nil {
+		log.Fatal("Update failed. | ", err)
+	}
+	fmt.Println(">>> Update")
+	fmt.Printf("%+v\n", updatedUser)
+	fmt.Println("")
+
+	// Delete
+	if err := users.Delete(createdUser.Id); err != nil {
+		log.Fatal("Delete failed. | ", err)
+	}
+
+	// Get
+	_, err = users.Get(createdUser.Id)
+	fmt.Println(">>> Get (Deleted)")
+	fmt.Println(err)
+	fmt.Println("")
+}
+
+func randomString() string {
+	seed := strconv.FormatInt(time.Now().UnixNano(), 10)
+	shaBytes := sha256.Sum256([]byte(seed))
+	return hex.EncodeToString(shaBytes[:])
+}
+
+func randomNumber() int {
+	rand.Seed(time.Now().UnixNano())
+	return rand.Intn(100-1) + 1
+}
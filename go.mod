@@ -1,3 +1,3 @@
 module github.com/xshoji/jsonbox-go
 
-go 1.13
+go 1.20
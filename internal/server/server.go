@@ -0,0 +1,606 @@
+// Package server implements the jsonbox REST API served by cmd/jsonboxd and jsonboxtest.
+package server
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xshoji/jsonbox-go/jsonboxgo"
+)
+
+// DefaultMaxRecordSize is the size limit of a record, 10KB like jsonbox.
+const DefaultMaxRecordSize = 10 * 1024
+
+var (
+	boxIdPattern      = regexp.MustCompile(`^[0-9A-Za-z_]{20,64}$`)
+	collectionPattern = regexp.MustCompile(`^[0-9A-Za-z_]{1,20}$`)
+	recordIdPattern   = regexp.MustCompile(`^[0-9a-f]{24}$`)
+	apiKeyPattern     = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+// Messages jsonbox responds with
+const (
+	messageInvalidJSON       = "Invalid JSON"
+	messageInvalidRecordId   = "Invalid record Id"
+	messageRecordNotFound    = "Record not found."
+	messageInvalidBoxId      = "Invalid box ID. Box ID should be 20 to 64 alphanumeric characters or underscores."
+	messageInvalidCollection = "Invalid collection name. Collection name should be 1 to 20 alphanumeric characters or underscores."
+	messageInvalidRequest    = "Invalid request"
+	messageInvalidAPIKey     = "Invalid API_KEY."
+)
+
+// Handler serves the jsonbox REST API.
+//
+// Records get an ObjectID-like "_id" and "_createdOn" (and "_updatedOn" after PUT) in milliseconds,
+// and "_createdOn" strictly increases so that the default sort "-_createdOn" is stable.
+// Keys starting with "_" in a posted record are dropped like jsonbox.
+// The filters, sort, offset and limit of GET are evaluated by jsonboxgo.Matcher.
+//
+// A box created by a request with an "x-api-key" header, a UUID, is protected:
+// anyone can read it but writing to it needs the same key.
+//
+// Records are kept in memory, and also in a log per box when the data directory is set.
+type Handler struct {
+	mutex         sync.Mutex
+	boxes         map[string]*box
+	now           func() time.Time
+	last          time.Time
+	counter       uint32
+	process       [5]byte
+	dataDir       string
+	maxRecordSize int
+	maxBoxSize    int
+}
+
+// Option configures the Handler.
+type Option func(*Handler)
+
+// WithClock sets the clock of "_createdOn" and "_updatedOn", time.Now by default.
+func WithClock(now func() time.Time) Option {
+	return func(h *Handler) {
+		h.now = now
+	}
+}
+
+// WithDataDir stores the records in an append-only log per box in dir, the logs are compacted by New.
+func WithDataDir(dir string) Option {
+	return func(h *Handler) {
+		h.dataDir = dir
+	}
+}
+
+// WithMaxRecordSize sets the size limit of a record in bytes, DefaultMaxRecordSize by default and 0 is no limit.
+func WithMaxRecordSize(size int) Option {
+	return func(h *Handler) {
+		h.maxRecordSize = size
+	}
+}
+
+// WithMaxBoxSize sets the size limit of all the records of a box in bytes, 0 (no limit) by default.
+func WithMaxBoxSize(size int) Option {
+	return func(h *Handler) {
+		h.maxBoxSize = size
+	}
+}
+
+type record struct {
+	id         string
+	collection string
+	createdOn  time.Time
+	updatedOn  time.Time
+	data       map[string]interface{}
+	raw        []byte
+	decoded    map[string]interface{}
+}
+
+type box struct {
+	records []*record
+	size    int
+	// SHA-256 of the api key of a protected box
+	apiKeyHash string
+	log        *boxLog
+}
+
+// Create new Handler, the logs of the data directory are loaded and compacted
+func New(opts ...Option) (*Handler, error) {
+	h := &Handler{boxes: make(map[string]*box), now: time.Now, maxRecordSize: DefaultMaxRecordSize}
+	for _, opt := range opts {
+		opt(h)
+	}
+	_, _ = rand.Read(h.process[:])
+	if h.dataDir != "" {
+		if err := h.load(); err != nil {
+			_ = h.Close()
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+// Close closes the logs.
+func (h *Handler) Close() error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	errs := make([]error, 0)
+	for _, b := range h.boxes {
+		if b.log != nil {
+			errs = append(errs, b.log.close())
+			b.log = nil
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Records returns the records of the collection in the order they were created, "" is every record of the box.
+func (h *Handler) Records(boxId string, collection string) [][]byte {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	records := make([][]byte, 0)
+	for _, r := range h.collectionRecords(boxId, collection) {
+		records = append(records, r.raw)
+	}
+	return records
+}
+
+// Reset removes every box, with their logs.
+func (h *Handler) Reset() error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	errs := make([]error, 0)
+	for _, b := range h.boxes {
+		if b.log != nil {
+			errs = append(errs, b.log.remove())
+		}
+	}
+	h.boxes = make(map[string]*box)
+	return errors.Join(errs...)
+}
+
+// Route /{box}, /{box}/{collection}, /{box}/{id} and /{box}/{collection}/{id}
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(segments) > 3 || segments[0] == "" {
+		writeMessage(w, http.StatusNotFound, "Not found")
+		return
+	}
+	boxId, collection, recordId := segments[0], "", ""
+	if !boxIdPattern.MatchString(boxId) {
+		writeMessage(w, http.StatusBadRequest, messageInvalidBoxId)
+		return
+	}
+	switch {
+	case len(segments) == 3:
+		collection, recordId = segments[1], segments[2]
+		if !recordIdPattern.MatchString(recordId) {
+			writeMessage(w, http.StatusBadRequest, messageInvalidRecordId)
+			return
+		}
+	case len(segments) == 2 && recordIdPattern.MatchString(segments[1]):
+		recordId = segments[1]
+	case len(segments) == 2:
+		collection = segments[1]
+	}
+	if collection != "" && !collectionPattern.MatchString(collection) {
+		writeMessage(w, http.StatusBadRequest, messageInvalidCollection)
+		return
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if r.Method != http.MethodGet && !h.authorize(w, r, boxId) {
+		return
+	}
+	switch {
+	case r.Method == http.MethodPost && recordId == "":
+		h.create(w, r, boxId, collection)
+	case r.Method == http.MethodGet && recordId == "":
+		h.list(w, r, boxId, collection)
+	case r.Method == http.MethodGet:
+		h.read(w, boxId, recordId)
+	case r.Method == http.MethodPut && recordId != "":
+		h.update(w, r, boxId, recordId)
+	case r.Method == http.MethodDelete && recordId != "":
+		h.delete(w, boxId, recordId)
+	case r.Method == http.MethodDelete:
+		h.deleteByQuery(w, r, boxId, collection)
+	default:
+		writeMessage(w, http.StatusBadRequest, messageInvalidRequest)
+	}
+}
+
+// Check the api key of the write request, the key must match the key of a protected box
+func (h *Handler) authorize(w http.ResponseWriter, r *http.Request, boxId string) bool {
+	apiKey := r.Header.Get("x-api-key")
+	if apiKey != "" && !apiKeyPattern.MatchString(apiKey) {
+		writeMessage(w, http.StatusUnauthorized, messageInvalidAPIKey)
+		return false
+	}
+	if b, ok := h.boxes[boxId]; ok && b.apiKeyHash != "" && hashAPIKey(apiKey) != b.apiKeyHash {
+		writeMessage(w, http.StatusUnauthorized, messageInvalidAPIKey)
+		return false
+	}
+	return true
+}
+
+// POST a record or an array of records
+func (h *Handler) create(w http.ResponseWriter, r *http.Request, boxId string, collection string) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeMessage(w, http.StatusBadRequest, messageInvalidJSON)
+		return
+	}
+	body = bytes.TrimSpace(body)
+	isArray := bytes.HasPrefix(body, []byte("["))
+	objects := make([]map[string]interface{}, 0)
+	if isArray {
+		err = decodeJSON(body, &objects)
+	} else {
+		object := make(map[string]interface{})
+		err = decodeJSON(body, &object)
+		objects = append(objects, object)
+	}
+	if err != nil || len(objects) == 0 {
+		writeMessage(w, http.StatusBadRequest, messageInvalidJSON)
+		return
+	}
+	for _, object := range objects {
+		if object == nil {
+			writeMessage(w, http.StatusBadRequest, messageInvalidJSON)
+			return
+		}
+	}
+
+	b, err := h.box(boxId)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	newRecords := make([]*record, 0, len(objects))
+	size := b.size
+	for _, object := range objects {
+		newRecord := &record{id: h.newObjectId(), collection: collection, createdOn: h.tick(), data: stripReserved(object)}
+		newRecord.encode()
+		if !h.checkSize(w, newRecord, size) {
+			return
+		}
+		size += len(newRecord.raw)
+		newRecords = append(newRecords, newRecord)
+	}
+	if apiKey := r.Header.Get("x-api-key"); apiKey != "" && b.apiKeyHash == "" && len(b.records) == 0 {
+		// the first POST to a box with a key protects the box
+		if err := b.append(logEntry{Op: opProtect, APIKeyHash: hashAPIKey(apiKey)}); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		b.apiKeyHash = hashAPIKey(apiKey)
+	}
+	created := make([]json.RawMessage, 0, len(newRecords))
+	for _, newRecord := range newRecords {
+		if err := b.append(putEntry(newRecord)); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		b.put(newRecord)
+		created = append(created, newRecord.raw)
+	}
+	if isArray {
+		writeJSON(w, http.StatusOK, created)
+		return
+	}
+	writeJSON(w, http.StatusOK, created[0])
+}
+
+// GET the records filtered by q, sorted by sort ("-_createdOn" by default), offset and limit
+func (h *Handler) list(w http.ResponseWriter, r *http.Request, boxId string, collection string) {
+	query, err := jsonboxgo.ParseQuery(r.URL.RawQuery)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if values, _ := url.ParseQuery(r.URL.RawQuery); values.Get("sort") == "" {
+		query.SortDesc("_createdOn")
+	}
+	matcher, err := jsonboxgo.NewMatcher(query)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	raws := make([][]byte, 0)
+	for _, c := range h.collectionRecords(boxId, collection) {
+		raws = append(raws, c.raw)
+	}
+	selected, err := matcher.ApplyJSON(raws)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	result := make([]json.RawMessage, 0, len(selected))
+	for _, raw := range selected {
+		result = append(result, raw)
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// GET a record by id
+func (h *Handler) read(w http.ResponseWriter, boxId string, recordId string) {
+	r := h.find(boxId, recordId)
+	if r == nil {
+		writeMessage(w, http.StatusNotFound, messageRecordNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, json.RawMessage(r.raw))
+}
+
+// PUT replaces the record, "_id" and "_createdOn" are kept
+func (h *Handler) update(w http.ResponseWriter, r *http.Request, boxId string, recordId string) {
+	target := h.find(boxId, recordId)
+	if target == nil {
+		writeMessage(w, http.StatusNotFound, messageRecordNotFound)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	object := make(map[string]interface{})
+	if err != nil || decodeJSON(body, &object) != nil || object == nil {
+		writeMessage(w, http.StatusBadRequest, messageInvalidJSON)
+		return
+	}
+	updated := &record{id: target.id, collection: target.collection, createdOn: target.createdOn, updatedOn: h.tick(), data: stripReserved(object)}
+	updated.encode()
+	b := h.boxes[boxId]
+	if !h.checkSize(w, updated, b.size-len(target.raw)) {
+		return
+	}
+	if err := b.append(putEntry(updated)); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	b.put(updated)
+	writeMessage(w, http.StatusOK, "Record updated.")
+}
+
+// DELETE a record by id
+func (h *Handler) delete(w http.ResponseWriter, boxId string, recordId string) {
+	if h.find(boxId, recordId) == nil {
+		writeMessage(w, http.StatusNotFound, messageRecordNotFound)
+		return
+	}
+	b := h.boxes[boxId]
+	if err := b.append(logEntry{Op: opDelete, Id: recordId}); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	b.remove(recordId)
+	writeMessage(w, http.StatusOK, "Record removed.")
+}
+
+// DELETE every record matched by q, q is required
+func (h *Handler) deleteByQuery(w http.ResponseWriter, r *http.Request, boxId string, collection string) {
+	values, err := url.ParseQuery(r.URL.RawQuery)
+	if err != nil || values.Get("q") == "" {
+		writeMessage(w, http.StatusBadRequest, messageInvalidRequest)
+		return
+	}
+	query, err := jsonboxgo.ParseQuery(r.URL.RawQuery)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	matcher, err := jsonboxgo.NewMatcher(query)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	removed := 0
+	for _, target := range h.collectionRecords(boxId, collection) {
+		if !matcher.Match(target.decoded) {
+			continue
+		}
+		b := h.boxes[boxId]
+		if err := b.append(logEntry{Op: opDelete, Id: target.id}); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		b.remove(target.id)
+		removed++
+	}
+	writeMessage(w, http.StatusOK, fmt.Sprintf("%d Records removed.", removed))
+}
+
+// Check the size of the record and of the box with it, the other records of the box are boxSize bytes
+func (h *Handler) checkSize(w http.ResponseWriter, r *record, boxSize int) bool {
+	if h.maxRecordSize > 0 && len(r.raw) > h.maxRecordSize {
+		writeMessage(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("JSON body is too large. Should be less than %d bytes.", h.maxRecordSize))
+		return false
+	}
+	if h.maxBoxSize > 0 && boxSize+len(r.raw) > h.maxBoxSize {
+		writeMessage(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("Box is full. Records should be less than %d bytes in total.", h.maxBoxSize))
+		return false
+	}
+	return true
+}
+
+// The box, created with its log when it does not exist
+func (h *Handler) box(boxId string) (*box, error) {
+	if b, ok := h.boxes[boxId]; ok {
+		return b, nil
+	}
+	b := &box{records: make([]*record, 0)}
+	if h.dataDir != "" {
+		log, err := openBoxLog(h.dataDir, boxId)
+		if err != nil {
+			return nil, err
+		}
+		b.log = log
+	}
+	h.boxes[boxId] = b
+	return b, nil
+}
+
+func (h *Handler) collectionRecords(boxId string, collection string) []*record {
+	records := make([]*record, 0)
+	if b, ok := h.boxes[boxId]; ok {
+		for _, r := range b.records {
+			if collection == "" || r.collection == collection {
+				records = append(records, r)
+			}
+		}
+	}
+	return records
+}
+
+func (h *Handler) find(boxId string, recordId string) *record {
+	if b, ok := h.boxes[boxId]; ok {
+		return b.find(recordId)
+	}
+	return nil
+}
+
+// The current time in milliseconds, strictly increasing
+func (h *Handler) tick() time.Time {
+	now := h.now().UTC().Truncate(time.Millisecond)
+	if !now.After(h.last) {
+		now = h.last.Add(time.Millisecond)
+	}
+	h.last = now
+	return now
+}
+
+// ObjectID-like id, 4 bytes of seconds, 5 bytes per server and 3 bytes of a counter
+func (h *Handler) newObjectId() string {
+	var id [12]byte
+	binary.BigEndian.PutUint32(id[0:4], uint32(h.now().Unix()))
+	copy(id[4:9], h.process[:])
+	h.counter++
+	id[9], id[10], id[11] = byte(h.counter>>16), byte(h.counter>>8), byte(h.counter)
+	return hex.EncodeToString(id[:])
+}
+
+// Add the record, or replace the record of the same id
+func (b *box) put(r *record) {
+	for i, existing := range b.records {
+		if existing.id == r.id {
+			b.size += len(r.raw) - len(existing.raw)
+			b.records[i] = r
+			return
+		}
+	}
+	b.size += len(r.raw)
+	b.records = append(b.records, r)
+}
+
+func (b *box) remove(recordId string) {
+	for i, r := range b.records {
+		if r.id == recordId {
+			b.size -= len(r.raw)
+			b.records = append(b.records[:i:i], b.records[i+1:]...)
+			return
+		}
+	}
+}
+
+func (b *box) find(recordId string) *record {
+	for _, r := range b.records {
+		if r.id == recordId {
+			return r
+		}
+	}
+	return nil
+}
+
+// Append the entry to the log, nothing is written without the data directory
+func (b *box) append(entry logEntry) error {
+	if b.log == nil {
+		return nil
+	}
+	return b.log.append(entry)
+}
+
+// Encode the record as jsonbox responds it, "_id" first and the timestamps last
+func (r *record) encode() {
+	keys := make([]string, 0, len(r.data))
+	for key := range r.data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var buffer bytes.Buffer
+	buffer.WriteString(`{"_id":`)
+	writeValue(&buffer, r.id)
+	for _, key := range keys {
+		buffer.WriteByte(',')
+		writeValue(&buffer, key)
+		buffer.WriteByte(':')
+		writeValue(&buffer, r.data[key])
+	}
+	buffer.WriteString(`,"_createdOn":`)
+	writeValue(&buffer, r.createdOn.Format(jsonboxgo.TimeFormat))
+	if !r.updatedOn.IsZero() {
+		buffer.WriteString(`,"_updatedOn":`)
+		writeValue(&buffer, r.updatedOn.Format(jsonboxgo.TimeFormat))
+	}
+	buffer.WriteByte('}')
+	r.raw = buffer.Bytes()
+	r.decoded = make(map[string]interface{})
+	_ = decodeJSON(r.raw, &r.decoded)
+}
+
+func writeValue(buffer *bytes.Buffer, value interface{}) {
+	encoded, _ := json.Marshal(value)
+	buffer.Write(encoded)
+}
+
+// Keys starting with "_" are reserved by jsonbox
+func stripReserved(object map[string]interface{}) map[string]interface{} {
+	for key := range object {
+		if strings.HasPrefix(key, "_") {
+			delete(object, key)
+		}
+	}
+	return object
+}
+
+func hashAPIKey(apiKey string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(apiKey)))
+	return hex.EncodeToString(sum[:])
+}
+
+// Decode keeping numbers as they are posted
+func decodeJSON(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	if decoder.More() {
+		return errors.New("server: trailing data after JSON value")
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(statusCode)
+	encoded, _ := json.Marshal(v)
+	_, _ = w.Write(encoded)
+}
+
+func writeMessage(w http.ResponseWriter, statusCode int, message string) {
+	writeJSON(w, statusCode, map[string]string{"message": message})
+}
+
+func writeError(w http.ResponseWriter, statusCode int, err error) {
+	writeMessage(w, statusCode, err.Error())
+}
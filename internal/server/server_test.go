@@ -0,0 +1,169 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/xshoji/jsonbox-go/jsonboxgo"
+)
+
+const (
+	testBoxId  = "box_0123456789abcdefghij"
+	testAPIKey = "0f8fad5b-d9cb-469f-a165-70867728950e"
+)
+
+// Create new Handler served by httptest.Server
+func newTestServer(t *testing.T, opts ...Option) (*Handler, *httptest.Server) {
+	t.Helper()
+	handler, err := New(opts...)
+	if err != nil {
+		t.Fatalf("  Failed: New() -> %v\n", err)
+	}
+	server := httptest.NewServer(handler)
+	t.Cleanup(func() {
+		server.Close()
+		_ = handler.Close()
+	})
+	return handler, server
+}
+
+func newTestClient(t *testing.T, server *httptest.Server, opts ...jsonboxgo.Option) jsonboxgo.Client {
+	t.Helper()
+	client, err := jsonboxgo.NewClient(server.URL, testBoxId, opts...)
+	if err != nil {
+		t.Fatalf("  Failed: NewClient() -> %v\n", err)
+	}
+	return client
+}
+
+func statusCode(err error) int {
+	var apiError *jsonboxgo.APIError
+	if errors.As(err, &apiError) {
+		return apiError.StatusCode
+	}
+	return 0
+}
+
+func TestProtectedBox(t *testing.T) {
+	_, server := newTestServer(t)
+	owner := newTestClient(t, server, jsonboxgo.WithAPIKey(testAPIKey))
+	body, err := owner.CreateWithError("users", map[string]string{"name": "taro"})
+	if err != nil {
+		t.Fatalf("  Failed: CreateWithError() -> %v\n", err)
+	}
+	var created jsonboxgo.Meta
+	if err := json.Unmarshal(body, &created); err != nil {
+		t.Fatalf("  Failed: json.Unmarshal() -> %v\n", err)
+	}
+
+	// test cases
+	testCases := map[string]struct {
+		InputOptions []jsonboxgo.Option
+		Input        func(client jsonboxgo.Client) error
+		Expected     int
+	}{
+		"Anyone can read.": {
+			Input: func(client jsonboxgo.Client) error {
+				_, err := client.ReadAllWithError("users")
+				return err
+			},
+			Expected: 0,
+		},
+		"Create without the key.": {
+			Input: func(client jsonboxgo.Client) error {
+				_, err := client.CreateWithError("users", map[string]string{"name": "jiro"})
+				return err
+			},
+			Expected: http.StatusUnauthorized,
+		},
+		"Update with another key.": {
+			InputOptions: []jsonboxgo.Option{jsonboxgo.WithAPIKey("1f8fad5b-d9cb-469f-a165-70867728950e")},
+			Input: func(client jsonboxgo.Client) error {
+				_, err := client.UpdateWithError("users", created.Id, map[string]string{"name": "jiro"})
+				return err
+			},
+			Expected: http.StatusUnauthorized,
+		},
+		"Delete with a key which is not a UUID.": {
+			InputOptions: []jsonboxgo.Option{jsonboxgo.WithAPIKey("secret")},
+			Input: func(client jsonboxgo.Client) error {
+				_, err := client.DeleteWithError("users", created.Id)
+				return err
+			},
+			Expected: http.StatusUnauthorized,
+		},
+		"Update with the key.": {
+			InputOptions: []jsonboxgo.Option{jsonboxgo.WithAPIKey(strings.ToUpper(testAPIKey))},
+			Input: func(client jsonboxgo.Client) error {
+				_, err := client.UpdateWithError("users", created.Id, map[string]string{"name": "taro"})
+				return err
+			},
+			Expected: 0,
+		},
+	}
+
+	// run
+	for testCase, param := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			client := newTestClient(t, server, param.InputOptions...)
+			actual := statusCode(param.Input(client))
+			expected := param.Expected
+			if actual != expected {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+			}
+		})
+	}
+}
+
+func TestSizeLimits(t *testing.T) {
+	// test cases
+	testCases := map[string]struct {
+		InputOptions []Option
+		InputRecords []interface{}
+		Expected     error
+	}{
+		"Record within the default limit.": {
+			InputRecords: []interface{}{map[string]string{"name": strings.Repeat("a", 10000)}},
+			Expected:     nil,
+		},
+		"Record over the default limit.": {
+			InputRecords: []interface{}{map[string]string{"name": strings.Repeat("a", 10240)}},
+			Expected:     jsonboxgo.ErrPayloadTooLarge,
+		},
+		"No record limit.": {
+			InputOptions: []Option{WithMaxRecordSize(0)},
+			InputRecords: []interface{}{map[string]string{"name": strings.Repeat("a", 20000)}},
+			Expected:     nil,
+		},
+		"Box over the limit.": {
+			InputOptions: []Option{WithMaxBoxSize(200)},
+			InputRecords: []interface{}{map[string]string{"name": "taro"}, map[string]string{"name": "jiro"}, map[string]string{"name": "saburo"}},
+			Expected:     jsonboxgo.ErrPayloadTooLarge,
+		},
+	}
+
+	// run
+	for testCase, param := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			handler, server := newTestServer(t, param.InputOptions...)
+			client := newTestClient(t, server)
+			var actual error
+			for _, record := range param.InputRecords {
+				if _, actual = client.CreateWithError("users", record); actual != nil {
+					break
+				}
+			}
+			expected := param.Expected
+			if !errors.Is(actual, expected) {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+			}
+			if expected != nil && len(handler.Records(testBoxId, "users")) == len(param.InputRecords) {
+				t.Errorf("  Failed: the record over the limit was stored\n")
+			}
+		})
+	}
+}
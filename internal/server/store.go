@@ -0,0 +1,248 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/xshoji/jsonbox-go/jsonboxgo"
+)
+
+// Operations of the log entries
+const (
+	opPut     = "put"
+	opDelete  = "delete"
+	opProtect = "protect"
+)
+
+const logExtension = ".log"
+
+// logEntry is a line of the log of a box.
+// put adds or replaces the record, delete removes the record of Id and protect sets the api key of the box.
+type logEntry struct {
+	Op         string          `json:"op"`
+	Collection string          `json:"collection,omitempty"`
+	Record     json.RawMessage `json:"record,omitempty"`
+	Id         string          `json:"id,omitempty"`
+	APIKeyHash string          `json:"apiKeyHash,omitempty"`
+}
+
+// boxLog is the append-only log of a box, {data dir}/{box id}.log.
+type boxLog struct {
+	path string
+	file *os.File
+}
+
+func putEntry(r *record) logEntry {
+	return logEntry{Op: opPut, Collection: r.collection, Record: r.raw}
+}
+
+func openBoxLog(dataDir string, boxId string) (*boxLog, error) {
+	path := filepath.Join(dataDir, boxId+logExtension)
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("server: failed to open the log: %w", err)
+	}
+	return &boxLog{path: path, file: file}, nil
+}
+
+// Append the entry as a line and sync it
+func (l *boxLog) append(entry logEntry) error {
+	line, err := encodeLogEntry(entry)
+	if err != nil {
+		return err
+	}
+	if _, err := l.file.Write(line); err != nil {
+		return fmt.Errorf("server: failed to write the log: %w", err)
+	}
+	if err := l.file.Sync(); err != nil {
+		return fmt.Errorf("server: failed to sync the log: %w", err)
+	}
+	return nil
+}
+
+// The entry as a line of the log
+func encodeLogEntry(entry logEntry) ([]byte, error) {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return nil, fmt.Errorf("server: failed to encode the log entry: %w", err)
+	}
+	return append(line, '\n'), nil
+}
+
+func (l *boxLog) close() error {
+	return l.file.Close()
+}
+
+// Close and delete the log
+func (l *boxLog) remove() error {
+	return errors.Join(l.file.Close(), os.Remove(l.path))
+}
+
+// Load the logs of the data directory, each log is compacted to the entries of its current state
+func (h *Handler) load() error {
+	if err := os.MkdirAll(h.dataDir, 0o755); err != nil {
+		return fmt.Errorf("server: failed to create the data directory: %w", err)
+	}
+	files, err := os.ReadDir(h.dataDir)
+	if err != nil {
+		return fmt.Errorf("server: failed to read the data directory: %w", err)
+	}
+	for _, file := range files {
+		boxId := strings.TrimSuffix(file.Name(), logExtension)
+		if file.IsDir() || !strings.HasSuffix(file.Name(), logExtension) || !boxIdPattern.MatchString(boxId) {
+			continue
+		}
+		path := filepath.Join(h.dataDir, file.Name())
+		b, err := readBoxLog(path)
+		if err != nil {
+			return err
+		}
+		if err := compactBoxLog(path, b); err != nil {
+			return err
+		}
+		if b.log, err = openBoxLog(h.dataDir, boxId); err != nil {
+			return err
+		}
+		h.boxes[boxId] = b
+		for _, r := range b.records {
+			if r.createdOn.After(h.last) {
+				h.last = r.createdOn
+			}
+			if r.updatedOn.After(h.last) {
+				h.last = r.updatedOn
+			}
+		}
+	}
+	return nil
+}
+
+// Replay the log, an incomplete last line left by a crash is ignored
+func readBoxLog(path string) (*box, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("server: failed to open the log: %w", err)
+	}
+	defer file.Close()
+	b := &box{records: make([]*record, 0)}
+	reader := bufio.NewReader(file)
+	for lineNumber := 1; ; lineNumber++ {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			return b, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("server: failed to read the log: %w", err)
+		}
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		if err := b.replay(line); err != nil {
+			return nil, fmt.Errorf("server: %s line %d: %w", path, lineNumber, err)
+		}
+	}
+}
+
+// Apply the log entry to the box
+func (b *box) replay(line []byte) error {
+	var entry logEntry
+	if err := json.Unmarshal(line, &entry); err != nil {
+		return err
+	}
+	switch entry.Op {
+	case opPut:
+		r, err := decodeLoggedRecord(entry)
+		if err != nil {
+			return err
+		}
+		b.put(r)
+	case opDelete:
+		b.remove(entry.Id)
+	case opProtect:
+		b.apiKeyHash = entry.APIKeyHash
+	default:
+		return fmt.Errorf("unknown operation %q", entry.Op)
+	}
+	return nil
+}
+
+// Decode the record of the put entry
+func decodeLoggedRecord(entry logEntry) (*record, error) {
+	object := make(map[string]interface{})
+	if err := decodeJSON(entry.Record, &object); err != nil {
+		return nil, err
+	}
+	id, _ := object["_id"].(string)
+	if !recordIdPattern.MatchString(id) {
+		return nil, fmt.Errorf("invalid record id %q", id)
+	}
+	r := &record{id: id, collection: entry.Collection}
+	var err error
+	if r.createdOn, err = parseLoggedTime(object["_createdOn"]); err != nil {
+		return nil, err
+	}
+	if _, ok := object["_updatedOn"]; ok {
+		if r.updatedOn, err = parseLoggedTime(object["_updatedOn"]); err != nil {
+			return nil, err
+		}
+	}
+	r.data = stripReserved(object)
+	r.encode()
+	return r, nil
+}
+
+func parseLoggedTime(value interface{}) (time.Time, error) {
+	text, _ := value.(string)
+	return time.Parse(jsonboxgo.TimeFormat, text)
+}
+
+// Rewrite the log with the entries of the current state of the box
+func compactBoxLog(path string, b *box) error {
+	entries := make([]logEntry, 0, len(b.records)+1)
+	if b.apiKeyHash != "" {
+		entries = append(entries, logEntry{Op: opProtect, APIKeyHash: b.apiKeyHash})
+	}
+	for _, r := range b.records {
+		entries = append(entries, putEntry(r))
+	}
+	var buffer bytes.Buffer
+	for _, entry := range entries {
+		line, err := encodeLogEntry(entry)
+		if err != nil {
+			return err
+		}
+		buffer.Write(line)
+	}
+	temporaryPath := path + ".tmp"
+	if err := writeFileSync(temporaryPath, buffer.Bytes()); err != nil {
+		_ = os.Remove(temporaryPath)
+		return fmt.Errorf("server: failed to compact the log: %w", err)
+	}
+	if err := os.Rename(temporaryPath, path); err != nil {
+		return fmt.Errorf("server: failed to compact the log: %w", err)
+	}
+	return nil
+}
+
+func writeFileSync(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
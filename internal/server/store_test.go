@@ -0,0 +1,88 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/xshoji/jsonbox-go/jsonboxgo"
+)
+
+func TestDataDir(t *testing.T) {
+	dataDir := t.TempDir()
+	logPath := filepath.Join(dataDir, testBoxId+logExtension)
+	now := time.Date(2020, 4, 26, 16, 26, 13, 0, time.UTC)
+	clock := WithClock(func() time.Time { return now })
+
+	handler, server := newTestServer(t, WithDataDir(dataDir), clock)
+	client := newTestClient(t, server, jsonboxgo.WithAPIKey(testAPIKey))
+	created, err := client.CreateMany("users", []interface{}{
+		map[string]string{"name": "taro"},
+		map[string]string{"name": "jiro"},
+		map[string]string{"name": "saburo"},
+	})
+	if err != nil {
+		t.Fatalf("  Failed: CreateMany() -> %v\n", err)
+	}
+	var first jsonboxgo.Meta
+	_ = json.Unmarshal(created[0], &first)
+	if _, err := client.UpdateWithError("users", first.Id, map[string]string{"name": "ichiro"}); err != nil {
+		t.Fatalf("  Failed: UpdateWithError() -> %v\n", err)
+	}
+	if _, err := client.DeleteByQuery("users", jsonboxgo.NewQueryBuilder().AndEqual("name", "jiro")); err != nil {
+		t.Fatalf("  Failed: DeleteByQuery() -> %v\n", err)
+	}
+	expected := handler.Records(testBoxId, "")
+	logBeforeRestart, _ := os.ReadFile(logPath)
+	server.Close()
+	_ = handler.Close()
+
+	// a crash in the middle of writing leaves an incomplete last line
+	file, _ := os.OpenFile(logPath, os.O_WRONLY|os.O_APPEND, 0o644)
+	_, _ = file.WriteString(`{"op":"put","collec`)
+	_ = file.Close()
+
+	restarted, restartedServer := newTestServer(t, WithDataDir(dataDir), clock)
+	actual := restarted.Records(testBoxId, "")
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("  Failed: actual -> %s, expected -> %s\n", actual, expected)
+	}
+	compacted, _ := os.ReadFile(logPath)
+	actualLines, expectedLines := bytes.Count(compacted, []byte("\n")), 3
+	if actualLines != expectedLines {
+		t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actualLines, actualLines, expectedLines, expectedLines)
+	}
+	if len(compacted) >= len(logBeforeRestart) {
+		t.Errorf("  Failed: the log was not compacted, %d bytes -> %d bytes\n", len(logBeforeRestart), len(compacted))
+	}
+
+	// the box stays protected and "_createdOn" keeps increasing after the restart
+	_, err = newTestClient(t, restartedServer).CreateWithError("users", map[string]string{"name": "shiro"})
+	if actualStatusCode := statusCode(err); actualStatusCode != http.StatusUnauthorized {
+		t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actualStatusCode, actualStatusCode, http.StatusUnauthorized, http.StatusUnauthorized)
+	}
+	body, err := newTestClient(t, restartedServer, jsonboxgo.WithAPIKey(testAPIKey)).CreateWithError("users", map[string]string{"name": "shiro"})
+	if err != nil {
+		t.Fatalf("  Failed: CreateWithError() -> %v\n", err)
+	}
+	var shiro jsonboxgo.Meta
+	_ = json.Unmarshal(body, &shiro)
+	actualCreatedOn, expectedCreatedOn := shiro.CreatedOn, "2020-04-26T16:26:13.004Z"
+	if actualCreatedOn != expectedCreatedOn {
+		t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actualCreatedOn, actualCreatedOn, expectedCreatedOn, expectedCreatedOn)
+	}
+}
+
+func TestDataDirInvalidLog(t *testing.T) {
+	dataDir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dataDir, testBoxId+logExtension), []byte("{\"op\":\"unknown\"}\n"), 0o644)
+	_, err := New(WithDataDir(dataDir))
+	if err == nil {
+		t.Errorf("  Failed: actual -> %v(%T), expected -> an error\n", err, err)
+	}
+}
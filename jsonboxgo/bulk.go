@@ -0,0 +1,192 @@
+package jsonboxgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultBatchRecords is the default max number of records sent by one request of CreateMany.
+	DefaultBatchRecords = 100
+	// DefaultBatchBytes is the default max request body size of CreateMany, same as jsonbox.io's limit.
+	DefaultBatchBytes = 10 * 1024
+	// DefaultBatchConcurrency is the default number of batches sent concurrently by CreateMany.
+	DefaultBatchConcurrency = 4
+)
+
+type batchConfig struct {
+	maxRecords  int
+	maxBytes    int
+	concurrency int
+}
+
+func defaultBatchConfig() batchConfig {
+	return batchConfig{
+		maxRecords:  DefaultBatchRecords,
+		maxBytes:    DefaultBatchBytes,
+		concurrency: DefaultBatchConcurrency,
+	}
+}
+
+// WithBatchLimits sets the max number of records and the max body size of one request of CreateMany.
+func WithBatchLimits(maxRecords int, maxBytes int) Option {
+	return func(c *DefaultClient) error {
+		if maxRecords < 1 || maxBytes < 1 {
+			return fmt.Errorf("jsonboxgo: batch limits must be positive: %d records, %d bytes", maxRecords, maxBytes)
+		}
+		c.batch.maxRecords = maxRecords
+		c.batch.maxBytes = maxBytes
+		return nil
+	}
+}
+
+// WithBatchConcurrency sets the number of batches sent concurrently by CreateMany.
+func WithBatchConcurrency(concurrency int) Option {
+	return func(c *DefaultClient) error {
+		if concurrency < 1 {
+			return fmt.Errorf("jsonboxgo: batch concurrency must be at least 1: %d", concurrency)
+		}
+		c.batch.concurrency = concurrency
+		return nil
+	}
+}
+
+// BatchFailure is a failed batch of CreateMany, records[Start:End] were not created.
+type BatchFailure struct {
+	Batch int
+	Start int
+	End   int
+	Err   error
+}
+
+// BatchError is returned when some batches of CreateMany failed.
+type BatchError struct {
+	Failures []BatchFailure
+}
+
+func (e *BatchError) Error() string {
+	messages := make([]string, 0, len(e.Failures))
+	for _, failure := range e.Failures {
+		messages = append(messages, "batch "+strconv.Itoa(failure.Batch)+" (records "+strconv.Itoa(failure.Start)+"-"+strconv.Itoa(failure.End-1)+"): "+failure.Err.Error())
+	}
+	return "jsonboxgo: " + strconv.Itoa(len(e.Failures)) + " batches failed: " + strings.Join(messages, "; ")
+}
+
+// Unwrap returns the errors of the failed batches.
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failures))
+	for _, failure := range e.Failures {
+		errs = append(errs, failure.Err)
+	}
+	return errs
+}
+
+// Create many records
+func (c DefaultClient) CreateMany(collection string, records []interface{}) ([][]byte, error) {
+	return c.CreateManyContext(context.Background(), collection, records)
+}
+
+// Create many records with context.
+// Records are split into batches and the batches are sent concurrently.
+// The created records are returned in input order, records of failed batches are nil and reported by *BatchError.
+func (c DefaultClient) CreateManyContext(ctx context.Context, collection string, records []interface{}) ([][]byte, error) {
+	encodedRecords := make([]json.RawMessage, len(records))
+	for i, record := range records {
+		encoded, err := json.Marshal(record)
+		if err != nil {
+			return nil, fmt.Errorf("jsonboxgo: json.Marshal() failed on record %d: %w", i, err)
+		}
+		encodedRecords[i] = encoded
+	}
+	batches := c.batch.split(encodedRecords)
+	created := make([][]byte, len(records))
+	failures := make([]BatchFailure, 0)
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, c.batch.concurrency)
+	for i, batch := range batches {
+		wg.Add(1)
+		go func(i int, batch recordBatch) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			createdRecords, err := c.createBatch(ctx, collection, encodedRecords[batch.start:batch.end], batch.tooLarge)
+			mutex.Lock()
+			defer mutex.Unlock()
+			if err != nil {
+				failures = append(failures, BatchFailure{Batch: i, Start: batch.start, End: batch.end, Err: err})
+				return
+			}
+			copy(created[batch.start:batch.end], createdRecords)
+		}(i, batch)
+	}
+	wg.Wait()
+	if len(failures) > 0 {
+		sort.Slice(failures, func(i, j int) bool { return failures[i].Batch < failures[j].Batch })
+		return created, &BatchError{Failures: failures}
+	}
+	return created, nil
+}
+
+// Send a batch as a json array
+func (c DefaultClient) createBatch(ctx context.Context, collection string, records []json.RawMessage, tooLarge bool) ([][]byte, error) {
+	if tooLarge {
+		return nil, fmt.Errorf("%w: record exceeds %d bytes", ErrPayloadTooLarge, c.batch.maxBytes)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("jsonboxgo: batch canceled: %w", err)
+	}
+	requestBody, _ := json.Marshal(records)
+	body, err := c.doWithRetry(ctx, "POST", c.requestUrl(collection, "", ""), requestBody)
+	if err != nil {
+		return nil, err
+	}
+	var createdRecords []json.RawMessage
+	if err := json.Unmarshal(body, &createdRecords); err != nil {
+		return nil, fmt.Errorf("jsonboxgo: json.Unmarshal() failed: %w", err)
+	}
+	if len(createdRecords) != len(records) {
+		return nil, errors.New("jsonboxgo: responded " + strconv.Itoa(len(createdRecords)) + " records for " + strconv.Itoa(len(records)) + " records")
+	}
+	result := make([][]byte, len(createdRecords))
+	for i, createdRecord := range createdRecords {
+		result[i] = createdRecord
+	}
+	return result, nil
+}
+
+// recordBatch is the range of records sent by one request.
+type recordBatch struct {
+	start    int
+	end      int
+	tooLarge bool
+}
+
+// Split records into batches within the limits, a record exceeding the size limit becomes its own batch
+func (b batchConfig) split(records []json.RawMessage) []recordBatch {
+	batches := make([]recordBatch, 0)
+	start, size := 0, 2 // "[" and "]"
+	for i, record := range records {
+		recordSize := len(record) + 1 // ","
+		if i > start && (i-start >= b.maxRecords || size+recordSize > b.maxBytes) {
+			batches = append(batches, recordBatch{start: start, end: i})
+			start, size = i, 2
+		}
+		if 2+len(record) > b.maxBytes {
+			batches = append(batches, recordBatch{start: i, end: i + 1, tooLarge: true})
+			start, size = i+1, 2
+			continue
+		}
+		size += recordSize
+	}
+	if start < len(records) {
+		batches = append(batches, recordBatch{start: start, end: len(records)})
+	}
+	return batches
+}
@@ -0,0 +1,113 @@
+package jsonboxgo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+func TestBatchSplit(t *testing.T) {
+	// test cases
+	testCases := map[string]struct {
+		InputConfig     batchConfig
+		InputRecords    []string
+		ExpectedBatches []recordBatch
+	}{
+		"Split by records.": {
+			InputConfig:     batchConfig{maxRecords: 2, maxBytes: 1024},
+			InputRecords:    []string{`{"a":1}`, `{"a":2}`, `{"a":3}`},
+			ExpectedBatches: []recordBatch{{start: 0, end: 2}, {start: 2, end: 3}},
+		},
+		"Split by bytes.": {
+			InputConfig:     batchConfig{maxRecords: 10, maxBytes: 18},
+			InputRecords:    []string{`{"a":1}`, `{"a":2}`, `{"a":3}`},
+			ExpectedBatches: []recordBatch{{start: 0, end: 2}, {start: 2, end: 3}},
+		},
+		"Too large record.": {
+			InputConfig:     batchConfig{maxRecords: 10, maxBytes: 18},
+			InputRecords:    []string{`{"a":1}`, `{"a":"too large record"}`, `{"a":3}`},
+			ExpectedBatches: []recordBatch{{start: 0, end: 1}, {start: 1, end: 2, tooLarge: true}, {start: 2, end: 3}},
+		},
+		"Empty.": {
+			InputConfig:     batchConfig{maxRecords: 10, maxBytes: 18},
+			InputRecords:    []string{},
+			ExpectedBatches: []recordBatch{},
+		},
+	}
+
+	// run
+	for testCase, param := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			records := make([]json.RawMessage, 0)
+			for _, record := range param.InputRecords {
+				records = append(records, json.RawMessage(record))
+			}
+			actual := param.InputConfig.split(records)
+			expected := param.ExpectedBatches
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+			}
+		})
+	}
+}
+
+func TestCreateMany(t *testing.T) {
+	var counter int64
+	// respond the posted records with ids, the batch containing "fail" responds 500
+	mockHttpClient := NewTestClient(func(req *http.Request) *http.Response {
+		var records []map[string]interface{}
+		_ = json.NewDecoder(req.Body).Decode(&records)
+		statusCode := 200
+		for _, record := range records {
+			if record["name"] == "fail" {
+				statusCode = 500
+			}
+			record["_id"] = "id" + strconv.FormatInt(atomic.AddInt64(&counter, 1), 10)
+		}
+		body, _ := json.Marshal(records)
+		if statusCode != 200 {
+			body = []byte(`{"message":"Internal Server Error"}`)
+		}
+		return &http.Response{
+			StatusCode: statusCode,
+			Body:       ioutil.NopCloser(bytes.NewBuffer(body)),
+			Header:     make(http.Header),
+		}
+	})
+	client, _ := NewClient("https://test.com", "box_test", WithHTTPClient(mockHttpClient), WithBatchLimits(2, 1024), WithBatchConcurrency(2))
+	inputRecords := []interface{}{User{Name: "a"}, User{Name: "b"}, User{Name: "fail"}, User{Name: "d"}, User{Name: "e"}}
+	created, err := client.CreateMany("users", inputRecords)
+
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || len(batchErr.Failures) != 1 {
+		t.Fatalf("  Failed: err -> %v(%T), expected -> *BatchError\n", err, err)
+	}
+	failure := batchErr.Failures[0]
+	if failure.Batch != 1 || failure.Start != 2 || failure.End != 4 {
+		t.Errorf("  Failed: failure -> %+v\n", failure)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 500 {
+		t.Errorf("  Failed: err -> %v(%T), expected -> *APIError\n", err, err)
+	}
+	expectedNames := []string{"a", "b", "", "", "e"}
+	for i, expected := range expectedNames {
+		var user User
+		if created[i] != nil {
+			_ = json.Unmarshal(created[i], &user)
+			if user.Id == "" {
+				t.Errorf("  Failed: record %d has no id\n", i)
+			}
+		}
+		actual := user.Name
+		if actual != expected {
+			t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+		}
+	}
+}
@@ -0,0 +1,303 @@
+package jsonboxgo
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheConfig configures CachedClient.
+type CacheConfig struct {
+	// TTL is how long a responded body is served from the cache.
+	TTL time.Duration
+	// MaxEntries is the number of cached bodies, the least recently used one is evicted beyond it.
+	MaxEntries int
+}
+
+// CacheStats is the snapshot of the counters of CachedClient.
+type CacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Entries   int
+}
+
+// CachedClient is a Client caching the successful responses of Read, ReadAll and ReadByQuery,
+// keyed by the collection, the record id and the built query.
+//
+// Create, Update, Delete, CreateMany, DeleteByQuery and UpdateByQuery invalidate the cached responses of their collection,
+// and of the whole box (collection ""). A write to collection "" invalidates every response.
+// Iterate is not cached. Writes made by other clients are seen only after TTL.
+type CachedClient struct {
+	Client
+	mutex     sync.Mutex
+	config    CacheConfig
+	entries   map[cacheKey]*list.Element
+	lru       *list.List
+	now       func() time.Time
+	writes    uint64
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+type cacheKey struct {
+	collection string
+	recordId   string
+	query      string
+}
+
+type cacheEntry struct {
+	key       cacheKey
+	body      []byte
+	expiresAt time.Time
+}
+
+// Create new CachedClient wrapping client
+func NewCachedClient(client Client, config CacheConfig) (*CachedClient, error) {
+	if client == nil {
+		return nil, fmt.Errorf("jsonboxgo: client must not be nil")
+	}
+	if config.TTL <= 0 {
+		return nil, fmt.Errorf("jsonboxgo: ttl must be positive: %v", config.TTL)
+	}
+	if config.MaxEntries < 1 {
+		return nil, fmt.Errorf("jsonboxgo: max entries must be at least 1: %d", config.MaxEntries)
+	}
+	return &CachedClient{
+		Client:  client,
+		config:  config,
+		entries: make(map[cacheKey]*list.Element),
+		lru:     list.New(),
+		now:     time.Now,
+	}, nil
+}
+
+// Stats returns the counters.
+func (c *CachedClient) Stats() CacheStats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return CacheStats{
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+		Entries:   c.lru.Len(),
+	}
+}
+
+// Invalidate removes the cached responses of the collection and of the whole box, "" removes every response.
+func (c *CachedClient) Invalidate(collection string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.invalidate(collection)
+}
+
+// Read all
+func (c *CachedClient) ReadAll(collection string) []byte {
+	body, err := c.ReadAllWithError(collection)
+	return bodyOrFatal("ReadAll", body, err)
+}
+
+// Read by query
+func (c *CachedClient) ReadByQuery(collection string, query Querier) []byte {
+	body, err := c.ReadByQueryWithError(collection, query)
+	return bodyOrFatal("ReadByQuery", body, err)
+}
+
+// Read one
+func (c *CachedClient) Read(collection string, recordId string) (respondedBody []byte, found bool) {
+	body, err := c.ReadWithError(collection, recordId)
+	return body, succeededOrFatal("Read", err)
+}
+
+// Read all, returns an error instead of exiting the process
+func (c *CachedClient) ReadAllWithError(collection string) ([]byte, error) {
+	return c.ReadAllContext(context.Background(), collection)
+}
+
+// Read by query, returns an error instead of exiting the process
+func (c *CachedClient) ReadByQueryWithError(collection string, query Querier) ([]byte, error) {
+	return c.ReadByQueryContext(context.Background(), collection, query)
+}
+
+// Read one, returns an error instead of exiting the process
+func (c *CachedClient) ReadWithError(collection string, recordId string) ([]byte, error) {
+	return c.ReadContext(context.Background(), collection, recordId)
+}
+
+// Read all with context
+func (c *CachedClient) ReadAllContext(ctx context.Context, collection string) ([]byte, error) {
+	return c.read(cacheKey{collection: cacheCollection(collection)}, func() ([]byte, error) {
+		return c.Client.ReadAllContext(ctx, collection)
+	})
+}
+
+// Read by query with context, an invalid query is not cached
+func (c *CachedClient) ReadByQueryContext(ctx context.Context, collection string, query Querier) ([]byte, error) {
+	if err := query.Err(); err != nil {
+		return nil, err
+	}
+	return c.read(cacheKey{collection: cacheCollection(collection), query: query.Build()}, func() ([]byte, error) {
+		return c.Client.ReadByQueryContext(ctx, collection, query)
+	})
+}
+
+// Read one with context
+func (c *CachedClient) ReadContext(ctx context.Context, collection string, recordId string) ([]byte, error) {
+	return c.read(cacheKey{collection: cacheCollection(collection), recordId: recordId}, func() ([]byte, error) {
+		return c.Client.ReadContext(ctx, collection, recordId)
+	})
+}
+
+// Create
+func (c *CachedClient) Create(collection string, object interface{}) []byte {
+	defer c.Invalidate(collection)
+	return c.Client.Create(collection, object)
+}
+
+// Update
+func (c *CachedClient) Update(collection string, recordId string, object interface{}) (respondedBody []byte, updated bool) {
+	defer c.Invalidate(collection)
+	return c.Client.Update(collection, recordId, object)
+}
+
+// Delete
+func (c *CachedClient) Delete(collection string, recordId string) (respondedBody []byte, deleted bool) {
+	defer c.Invalidate(collection)
+	return c.Client.Delete(collection, recordId)
+}
+
+// Create, returns an error instead of exiting the process
+func (c *CachedClient) CreateWithError(collection string, object interface{}) ([]byte, error) {
+	return c.CreateContext(context.Background(), collection, object)
+}
+
+// Update, returns an error instead of exiting the process
+func (c *CachedClient) UpdateWithError(collection string, recordId string, object interface{}) ([]byte, error) {
+	return c.UpdateContext(context.Background(), collection, recordId, object)
+}
+
+// Delete, returns an error instead of exiting the process
+func (c *CachedClient) DeleteWithError(collection string, recordId string) ([]byte, error) {
+	return c.DeleteContext(context.Background(), collection, recordId)
+}
+
+// Create with context
+func (c *CachedClient) CreateContext(ctx context.Context, collection string, object interface{}) ([]byte, error) {
+	defer c.Invalidate(collection)
+	return c.Client.CreateContext(ctx, collection, object)
+}
+
+// Update with context
+func (c *CachedClient) UpdateContext(ctx context.Context, collection string, recordId string, object interface{}) ([]byte, error) {
+	defer c.Invalidate(collection)
+	return c.Client.UpdateContext(ctx, collection, recordId, object)
+}
+
+// Delete with context
+func (c *CachedClient) DeleteContext(ctx context.Context, collection string, recordId string) ([]byte, error) {
+	defer c.Invalidate(collection)
+	return c.Client.DeleteContext(ctx, collection, recordId)
+}
+
+// Create many records
+func (c *CachedClient) CreateMany(collection string, records []interface{}) ([][]byte, error) {
+	return c.CreateManyContext(context.Background(), collection, records)
+}
+
+// Create many records with context
+func (c *CachedClient) CreateManyContext(ctx context.Context, collection string, records []interface{}) ([][]byte, error) {
+	defer c.Invalidate(collection)
+	return c.Client.CreateManyContext(ctx, collection, records)
+}
+
+// Delete records matched by query
+func (c *CachedClient) DeleteByQuery(collection string, query Querier, opts ...BulkOption) (BulkResult, error) {
+	return c.DeleteByQueryContext(context.Background(), collection, query, opts...)
+}
+
+// Delete records matched by query with context
+func (c *CachedClient) DeleteByQueryContext(ctx context.Context, collection string, query Querier, opts ...BulkOption) (BulkResult, error) {
+	defer c.Invalidate(collection)
+	return c.Client.DeleteByQueryContext(ctx, collection, query, opts...)
+}
+
+// Update records matched by query
+func (c *CachedClient) UpdateByQuery(collection string, query Querier, mutate MutateFunc, opts ...BulkOption) (BulkResult, error) {
+	return c.UpdateByQueryContext(context.Background(), collection, query, mutate, opts...)
+}
+
+// Update records matched by query with context
+func (c *CachedClient) UpdateByQueryContext(ctx context.Context, collection string, query Querier, mutate MutateFunc, opts ...BulkOption) (BulkResult, error) {
+	defer c.Invalidate(collection)
+	return c.Client.UpdateByQueryContext(ctx, collection, query, mutate, opts...)
+}
+
+// Serve the body from the cache, or fetch and cache it when no write happened meanwhile
+func (c *CachedClient) read(key cacheKey, fetch func() ([]byte, error)) ([]byte, error) {
+	c.mutex.Lock()
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*cacheEntry)
+		if c.now().Before(entry.expiresAt) {
+			c.hits++
+			c.lru.MoveToFront(element)
+			c.mutex.Unlock()
+			return bytes.Clone(entry.body), nil
+		}
+		c.remove(element)
+	}
+	c.misses++
+	writes := c.writes
+	c.mutex.Unlock()
+
+	body, err := fetch()
+	if err != nil {
+		return body, err
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.writes == writes {
+		c.put(key, bytes.Clone(body))
+	}
+	return body, nil
+}
+
+// Add the body as the most recently used entry, evicting the least recently used ones
+func (c *CachedClient) put(key cacheKey, body []byte) {
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key: key, body: body, expiresAt: c.now().Add(c.config.TTL)})
+	for c.lru.Len() > c.config.MaxEntries {
+		c.remove(c.lru.Back())
+		c.evictions++
+	}
+}
+
+func (c *CachedClient) remove(element *list.Element) {
+	c.lru.Remove(element)
+	delete(c.entries, element.Value.(*cacheEntry).key)
+}
+
+// Remove the entries of the collection and of the whole box, and drop the responses being fetched
+func (c *CachedClient) invalidate(collection string) {
+	c.writes++
+	collection = cacheCollection(collection)
+	for element := c.lru.Front(); element != nil; {
+		next := element.Next()
+		key := element.Value.(*cacheEntry).key
+		if collection == "" || key.collection == "" || key.collection == collection {
+			c.remove(element)
+		}
+		element = next
+	}
+}
+
+// The collection as it is requested, "/users" is "users"
+func cacheCollection(collection string) string {
+	return strings.Trim(collection, "/")
+}
@@ -0,0 +1,199 @@
+package jsonboxgo
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNewCachedClientValidation(t *testing.T) {
+	client := NewTestJsonboxClient(http.DefaultClient)
+	// test cases
+	testCases := map[string]struct {
+		InputClient   Client
+		InputConfig   CacheConfig
+		ExpectedError bool
+	}{
+		"Valid.":             {InputClient: client, InputConfig: CacheConfig{TTL: time.Second, MaxEntries: 1}},
+		"Nil client.":        {InputClient: nil, InputConfig: CacheConfig{TTL: time.Second, MaxEntries: 1}, ExpectedError: true},
+		"Zero ttl.":          {InputClient: client, InputConfig: CacheConfig{TTL: 0, MaxEntries: 1}, ExpectedError: true},
+		"Zero max entries.":  {InputClient: client, InputConfig: CacheConfig{TTL: time.Second, MaxEntries: 0}, ExpectedError: true},
+		"Negative duration.": {InputClient: client, InputConfig: CacheConfig{TTL: -time.Second, MaxEntries: 1}, ExpectedError: true},
+	}
+
+	// run
+	for testCase, param := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			_, err := NewCachedClient(param.InputClient, param.InputConfig)
+			if (err != nil) != param.ExpectedError {
+				t.Errorf("  Failed: err -> %v(%T), expectedError -> %v\n", err, err, param.ExpectedError)
+			}
+		})
+	}
+}
+
+func TestCachedClient(t *testing.T) {
+	ok := TestResponse{StatusCode: 200, Body: `[]`}
+	record := TestResponse{StatusCode: 200, Body: `{"_id":"id001"}`}
+	readAll := func(collection string) func(c *CachedClient, clock *time.Time) {
+		return func(c *CachedClient, clock *time.Time) {
+			_, _ = c.ReadAllWithError(collection)
+		}
+	}
+	// test cases
+	testCases := map[string]struct {
+		InputMaxEntries       int
+		InputSteps            []func(c *CachedClient, clock *time.Time)
+		InputResponses        []TestResponse
+		ExpectedRequestsCount int
+		ExpectedStats         CacheStats
+	}{
+		"Repeated reads are served from the cache.": {
+			InputSteps: []func(c *CachedClient, clock *time.Time){
+				readAll("users"),
+				readAll("users"),
+				func(c *CachedClient, clock *time.Time) {
+					_, _ = c.ReadByQueryWithError("users", NewQueryBuilder().Limit(1))
+					_, _ = c.ReadByQueryWithError("users", NewQueryBuilder().Limit(1))
+					_, _ = c.ReadByQueryWithError("users", NewQueryBuilder().Limit(2))
+				},
+				func(c *CachedClient, clock *time.Time) {
+					_, _ = c.ReadWithError("users", "id001")
+					_, _ = c.ReadWithError("users", "id001")
+				},
+				readAll("/users"),
+			},
+			InputResponses:        []TestResponse{ok, ok, ok, record},
+			ExpectedRequestsCount: 4,
+			ExpectedStats:         CacheStats{Hits: 4, Misses: 4, Entries: 4},
+		},
+		"Expired after TTL.": {
+			InputSteps: []func(c *CachedClient, clock *time.Time){
+				readAll("users"),
+				func(c *CachedClient, clock *time.Time) {
+					*clock = clock.Add(time.Minute)
+				},
+				readAll("users"),
+			},
+			InputResponses:        []TestResponse{ok, ok},
+			ExpectedRequestsCount: 2,
+			ExpectedStats:         CacheStats{Hits: 0, Misses: 2, Entries: 1},
+		},
+		"Least recently used is evicted.": {
+			InputMaxEntries: 2,
+			InputSteps: []func(c *CachedClient, clock *time.Time){
+				readAll("users"),
+				readAll("pets"),
+				readAll("users"),
+				readAll("items"),
+				readAll("pets"),
+			},
+			InputResponses:        []TestResponse{ok, ok, ok, ok},
+			ExpectedRequestsCount: 4,
+			ExpectedStats:         CacheStats{Hits: 1, Misses: 4, Evictions: 2, Entries: 2},
+		},
+		"Write invalidates the collection and the box.": {
+			InputSteps: []func(c *CachedClient, clock *time.Time){
+				readAll("users"),
+				readAll("pets"),
+				readAll(""),
+				func(c *CachedClient, clock *time.Time) {
+					_, _ = c.CreateWithError("users", map[string]string{"name": "taro"})
+				},
+				readAll("users"),
+				readAll("pets"),
+				readAll(""),
+			},
+			InputResponses:        []TestResponse{ok, ok, ok, record, ok, ok},
+			ExpectedRequestsCount: 6,
+			ExpectedStats:         CacheStats{Hits: 1, Misses: 5, Entries: 3},
+		},
+		"Write to the box invalidates every collection.": {
+			InputSteps: []func(c *CachedClient, clock *time.Time){
+				readAll("users"),
+				func(c *CachedClient, clock *time.Time) {
+					_, _ = c.DeleteWithError("", "id001")
+				},
+				readAll("users"),
+			},
+			InputResponses:        []TestResponse{ok, {StatusCode: 200, Body: `{"message":"Record removed."}`}, ok},
+			ExpectedRequestsCount: 3,
+			ExpectedStats:         CacheStats{Hits: 0, Misses: 2, Entries: 1},
+		},
+		"Errors are not cached.": {
+			InputSteps: []func(c *CachedClient, clock *time.Time){
+				readAll("users"),
+				readAll("users"),
+			},
+			InputResponses:        []TestResponse{{StatusCode: 404, Body: `{"message":"Not found"}`}, ok},
+			ExpectedRequestsCount: 2,
+			ExpectedStats:         CacheStats{Hits: 0, Misses: 2, Entries: 1},
+		},
+	}
+
+	// run
+	for testCase, param := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			requests := make([]*http.Request, 0)
+			client := NewTestJsonboxClient(CreateNewSequenceTestClient(param.InputResponses, &requests))
+			maxEntries := param.InputMaxEntries
+			if maxEntries == 0 {
+				maxEntries = 10
+			}
+			cached, err := NewCachedClient(client, CacheConfig{TTL: time.Minute, MaxEntries: maxEntries})
+			if err != nil {
+				t.Fatalf("  Failed: err -> %v(%T)\n", err, err)
+			}
+			clock := time.Date(2020, 4, 26, 0, 0, 0, 0, time.UTC)
+			cached.now = func() time.Time { return clock }
+			for _, step := range param.InputSteps {
+				step(cached, &clock)
+			}
+			actual := len(requests)
+			expected := param.ExpectedRequestsCount
+			if actual != expected {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+			}
+			actualStats := cached.Stats()
+			if actualStats != param.ExpectedStats {
+				t.Errorf("  Failed: actual -> %+v, expected -> %+v\n", actualStats, param.ExpectedStats)
+			}
+		})
+	}
+}
+
+func TestCachedClientWriteDuringRead(t *testing.T) {
+	var cached *CachedClient
+	requestsCount := 0
+	client := NewTestJsonboxClient(NewTestClient(func(req *http.Request) *http.Response {
+		requestsCount++
+		if requestsCount == 1 {
+			// the response read before the write completes is stale
+			cached.Invalidate("users")
+		}
+		return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBufferString(`[]`)), Header: make(http.Header)}
+	}))
+	cached, _ = NewCachedClient(client, CacheConfig{TTL: time.Minute, MaxEntries: 10})
+	for i := 0; i < 3; i++ {
+		_, _ = cached.ReadAllWithError("users")
+	}
+	actual, expected := requestsCount, 2
+	if actual != expected {
+		t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+	}
+}
+
+func TestCachedClientBodyIsCopied(t *testing.T) {
+	requests := make([]*http.Request, 0)
+	client := NewTestJsonboxClient(CreateNewSequenceTestClient([]TestResponse{{StatusCode: 200, Body: `[{"_id":"id001"}]`}}, &requests))
+	cached, _ := NewCachedClient(client, CacheConfig{TTL: time.Minute, MaxEntries: 10})
+	body, _ := cached.ReadAllWithError("users")
+	body[0] = 'x'
+	body, _ = cached.ReadAllWithError("users")
+	actual, expected := string(body), `[{"_id":"id001"}]`
+	if actual != expected {
+		t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+	}
+}
@@ -0,0 +1,117 @@
+package jsonboxgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Meta holds the fields jsonbox adds to every record.
+// Embed it into a record struct to get them filled by Collection.
+type Meta struct {
+	Id        string `json:"_id,omitempty"`
+	CreatedOn string `json:"_createdOn,omitempty"`
+	UpdatedOn string `json:"_updatedOn,omitempty"`
+}
+
+// Collection is a typed repository of a jsonbox collection.
+// The responded "_id", "_createdOn" and "_updatedOn" are decoded back into T,
+// so T should have fields tagged with them (or embed Meta).
+type Collection[T any] struct {
+	client Client
+	name   string
+}
+
+// Create new typed Collection bound to client and collection name
+func NewCollection[T any](client Client, name string) *Collection[T] {
+	return &Collection[T]{
+		client: client,
+		name:   name,
+	}
+}
+
+// Create
+func (c *Collection[T]) Create(record T) (T, error) {
+	return c.CreateContext(context.Background(), record)
+}
+
+// Get one by recordId
+func (c *Collection[T]) Get(recordId string) (T, error) {
+	return c.GetContext(context.Background(), recordId)
+}
+
+// List by query, nil query lists all records
+func (c *Collection[T]) List(query Querier) ([]T, error) {
+	return c.ListContext(context.Background(), query)
+}
+
+// Update
+func (c *Collection[T]) Update(recordId string, record T) (T, error) {
+	return c.UpdateContext(context.Background(), recordId, record)
+}
+
+// Delete
+func (c *Collection[T]) Delete(recordId string) error {
+	return c.DeleteContext(context.Background(), recordId)
+}
+
+// Create with context
+func (c *Collection[T]) CreateContext(ctx context.Context, record T) (T, error) {
+	body, err := c.client.CreateContext(ctx, c.name, record)
+	if err != nil {
+		return record, err
+	}
+	return decodeInto(record, body)
+}
+
+// Get one by recordId with context
+func (c *Collection[T]) GetContext(ctx context.Context, recordId string) (T, error) {
+	var record T
+	body, err := c.client.ReadContext(ctx, c.name, recordId)
+	if err != nil {
+		return record, err
+	}
+	return decodeInto(record, body)
+}
+
+// List by query with context, nil query lists all records
+func (c *Collection[T]) ListContext(ctx context.Context, query Querier) ([]T, error) {
+	var body []byte
+	var err error
+	if query == nil {
+		body, err = c.client.ReadAllContext(ctx, c.name)
+	} else {
+		body, err = c.client.ReadByQueryContext(ctx, c.name, query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	records := make([]T, 0)
+	if err := json.Unmarshal(body, &records); err != nil {
+		return nil, fmt.Errorf("jsonboxgo: json.Unmarshal() failed: %w", err)
+	}
+	return records, nil
+}
+
+// Update with context
+func (c *Collection[T]) UpdateContext(ctx context.Context, recordId string, record T) (T, error) {
+	body, err := c.client.UpdateContext(ctx, c.name, recordId, record)
+	if err != nil {
+		return record, err
+	}
+	return decodeInto(record, body)
+}
+
+// Delete with context
+func (c *Collection[T]) DeleteContext(ctx context.Context, recordId string) error {
+	_, err := c.client.DeleteContext(ctx, c.name, recordId)
+	return err
+}
+
+// Decode the responded body over a copy of record
+func decodeInto[T any](record T, body []byte) (T, error) {
+	if err := json.Unmarshal(body, &record); err != nil {
+		return record, fmt.Errorf("jsonboxgo: json.Unmarshal() failed: %w", err)
+	}
+	return record, nil
+}
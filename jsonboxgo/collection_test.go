@@ -0,0 +1,84 @@
+package jsonboxgo
+
+import (
+	"errors"
+	"testing"
+)
+
+type Book struct {
+	Meta
+	Title string `json:"title,omitempty"`
+	Pages int    `json:"pages,omitempty"`
+}
+
+func TestCollectionCreate(t *testing.T) {
+	mockHttpClient := CreateNewTestClient(200, `{"_id":"id001","title":"go","pages":100,"_createdOn":"2020-04-26T16:26:13.935Z"}`, 0, ``)
+	books := NewCollection[Book](NewTestJsonboxClient(mockHttpClient), "books")
+	actual, err := books.Create(Book{Title: "go", Pages: 100})
+	expected := Book{Meta: Meta{Id: "id001", CreatedOn: "2020-04-26T16:26:13.935Z"}, Title: "go", Pages: 100}
+	if err != nil || actual != expected {
+		t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T), err -> %v\n", actual, actual, expected, expected, err)
+	}
+}
+
+func TestCollectionGet(t *testing.T) {
+	// test cases
+	testCases := map[string]struct {
+		InputRespondedHttpStatus int
+		InputRespondedBody       string
+		ExpectedBook             Book
+		ExpectedError            error
+	}{
+		"Found case.": {
+			InputRespondedHttpStatus: 200,
+			InputRespondedBody:       `{"_id":"id001","title":"go","_createdOn":"2020-04-26T16:26:13.935Z","_updatedOn":"2020-04-27T16:26:13.935Z"}`,
+			ExpectedBook:             Book{Meta: Meta{Id: "id001", CreatedOn: "2020-04-26T16:26:13.935Z", UpdatedOn: "2020-04-27T16:26:13.935Z"}, Title: "go"},
+		},
+		"Not found case.": {
+			InputRespondedHttpStatus: 500,
+			InputRespondedBody:       `{"message":"Cannot read property '_id' of null"}`,
+			ExpectedError:            ErrNotFound,
+		},
+	}
+
+	// run
+	for testCase, param := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			mockHttpClient := CreateNewTestClient(param.InputRespondedHttpStatus, param.InputRespondedBody, 0, ``)
+			books := NewCollection[Book](NewTestJsonboxClient(mockHttpClient), "books")
+			actual, err := books.Get("id001")
+			expected := param.ExpectedBook
+			if actual != expected {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+			}
+			if param.ExpectedError != nil && !errors.Is(err, param.ExpectedError) {
+				t.Errorf("  Failed: err -> %v(%T), expected -> %v\n", err, err, param.ExpectedError)
+			}
+		})
+	}
+}
+
+func TestCollectionList(t *testing.T) {
+	mockHttpClient := CreateNewTestClient(200, `[{"_id":"id001","title":"go"},{"_id":"id002","title":"rust"}]`, 0, ``)
+	books := NewCollection[Book](NewTestJsonboxClient(mockHttpClient), "books")
+	actual, err := books.List(NewQueryBuilder().Limit(2))
+	if err != nil || len(actual) != 2 || actual[0].Id != "id001" || actual[1].Title != "rust" {
+		t.Errorf("  Failed: actual -> %v(%T), err -> %v\n", actual, actual, err)
+	}
+}
+
+func TestCollectionUpdateAndDelete(t *testing.T) {
+	mockHttpClient := CreateNewTestClient(200, `{"message":"Record updated."}`, 200, `{"_id":"id001","title":"go2","_updatedOn":"2020-04-27T16:26:13.935Z"}`)
+	books := NewCollection[Book](NewTestJsonboxClient(mockHttpClient), "books")
+	actual, err := books.Update("id001", Book{Title: "go2"})
+	expected := Book{Meta: Meta{Id: "id001", UpdatedOn: "2020-04-27T16:26:13.935Z"}, Title: "go2"}
+	if err != nil || actual != expected {
+		t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T), err -> %v\n", actual, actual, expected, expected, err)
+	}
+
+	mockHttpClient = CreateNewTestClient(400, `{"message":"Invalid record Id"}`, 0, ``)
+	books = NewCollection[Book](NewTestJsonboxClient(mockHttpClient), "books")
+	if err := books.Delete("id001"); !errors.Is(err, ErrInvalidID) {
+		t.Errorf("  Failed: err -> %v(%T), expected -> %v\n", err, err, ErrInvalidID)
+	}
+}
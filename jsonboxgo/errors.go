@@ -0,0 +1,79 @@
+package jsonboxgo
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrNotFound is matched when the requested record does not exist.
+	ErrNotFound = errors.New("jsonboxgo: record not found")
+	// ErrRateLimited is matched when jsonbox rejected the request by its rate limit.
+	ErrRateLimited = errors.New("jsonboxgo: rate limited")
+	// ErrInvalidID is matched when jsonbox rejected the record id.
+	ErrInvalidID = errors.New("jsonboxgo: invalid record id")
+	// ErrPayloadTooLarge is matched when jsonbox rejected the request body size.
+	ErrPayloadTooLarge = errors.New("jsonboxgo: payload too large")
+	// ErrRateLimitExceeded is returned when the client-side rate limiter is exhausted in fail fast mode.
+	ErrRateLimitExceeded = errors.New("jsonboxgo: client-side rate limit exceeded")
+)
+
+// APIError is returned when jsonbox responded with a non-2xx status code.
+// Use errors.Is with the sentinel errors to classify it.
+type APIError struct {
+	StatusCode int
+	Message    string
+	Method     string
+	URL        string
+	Body       []byte
+	Header     http.Header
+}
+
+// Create new APIError from the responded status code and body
+func newAPIError(method string, requestUrl string, statusCode int, body []byte) *APIError {
+	return &APIError{
+		StatusCode: statusCode,
+		Message:    parseMessage(statusCode, body),
+		Method:     method,
+		URL:        requestUrl,
+		Body:       body,
+	}
+}
+
+func (e *APIError) Error() string {
+	return "jsonboxgo: " + e.Method + " " + e.URL + " responded " + strconv.Itoa(e.StatusCode) + ": " + e.Message
+}
+
+// Is reports whether the error matches one of the sentinel errors.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		// jsonbox responds 500 for a well-formed but unknown record id.
+		return e.StatusCode == http.StatusNotFound ||
+			(e.StatusCode == http.StatusInternalServerError && strings.Contains(e.Message, "of null"))
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrInvalidID:
+		return e.StatusCode == http.StatusBadRequest && strings.Contains(strings.ToLower(e.Message), "invalid record id")
+	case ErrPayloadTooLarge:
+		return e.StatusCode == http.StatusRequestEntityTooLarge
+	}
+	return false
+}
+
+// Extract {"message": ...} from the responded body, fall back to the status text
+func parseMessage(statusCode int, body []byte) string {
+	var messageObject struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &messageObject) == nil && messageObject.Message != "" {
+		return messageObject.Message
+	}
+	if text := strings.TrimSpace(string(body)); text != "" && !strings.HasPrefix(text, "{") && !strings.HasPrefix(text, "<") {
+		return text
+	}
+	return http.StatusText(statusCode)
+}
@@ -0,0 +1,84 @@
+package jsonboxgo
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAPIError(t *testing.T) {
+	// test cases
+	InputBaseUrl := "https://test.com"
+	InputBoxId := "box_test"
+	testCases := map[string]struct {
+		InputRespondedHttpStatus int
+		InputRespondedBody       string
+		ExpectedMessage          string
+		ExpectedSentinel         error
+	}{
+		"Not found.": {
+			InputRespondedHttpStatus: 404,
+			InputRespondedBody:       `{"message":"Not Found"}`,
+			ExpectedMessage:          "Not Found",
+			ExpectedSentinel:         ErrNotFound,
+		},
+		"Unknown record id.": {
+			InputRespondedHttpStatus: 500,
+			InputRespondedBody:       `{"message":"Cannot read property '_id' of null"}`,
+			ExpectedMessage:          "Cannot read property '_id' of null",
+			ExpectedSentinel:         ErrNotFound,
+		},
+		"Invalid record id.": {
+			InputRespondedHttpStatus: 400,
+			InputRespondedBody:       `{"message":"Invalid record Id"}`,
+			ExpectedMessage:          "Invalid record Id",
+			ExpectedSentinel:         ErrInvalidID,
+		},
+		"Rate limited.": {
+			InputRespondedHttpStatus: 429,
+			InputRespondedBody:       `Too many requests, please try again later.`,
+			ExpectedMessage:          "Too many requests, please try again later.",
+			ExpectedSentinel:         ErrRateLimited,
+		},
+		"Payload too large.": {
+			InputRespondedHttpStatus: 413,
+			InputRespondedBody:       ``,
+			ExpectedMessage:          "Request Entity Too Large",
+			ExpectedSentinel:         ErrPayloadTooLarge,
+		},
+	}
+
+	// run
+	for testCase, param := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			mockHttpClient := CreateNewTestClient(param.InputRespondedHttpStatus, param.InputRespondedBody, 0, ``)
+			client, _ := NewClient(InputBaseUrl, InputBoxId, WithHTTPClient(mockHttpClient))
+			_, err := client.ReadWithError("users", "id001")
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("  Failed: err -> %v(%T), expected -> *APIError\n", err, err)
+			}
+			actual := apiErr.Message
+			expected := param.ExpectedMessage
+			if actual != expected {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+			}
+			if apiErr.Method != "GET" || apiErr.URL != "https://test.com/box_test/users/id001" || string(apiErr.Body) != param.InputRespondedBody {
+				t.Errorf("  Failed: request details -> %v %v %v\n", apiErr.Method, apiErr.URL, string(apiErr.Body))
+			}
+			for _, sentinel := range []error{ErrNotFound, ErrInvalidID, ErrRateLimited, ErrPayloadTooLarge} {
+				if errors.Is(err, sentinel) != (sentinel == param.ExpectedSentinel) {
+					t.Errorf("  Failed: errors.Is(%v, %v) -> %v\n", err, sentinel, errors.Is(err, sentinel))
+				}
+			}
+		})
+	}
+}
+
+func TestReadListIsNotFound(t *testing.T) {
+	mockHttpClient := CreateNewTestClient(200, `[{"_id":"id002","name":"taro"}]`, 0, ``)
+	client, _ := NewClient("https://test.com", "box_test", WithHTTPClient(mockHttpClient))
+	_, err := client.ReadWithError("users", "id001")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("  Failed: err -> %v(%T), expected -> %v\n", err, err, ErrNotFound)
+	}
+}
@@ -0,0 +1,101 @@
+package jsonboxgo
+
+import (
+	"fmt"
+	"strings"
+)
+
+// pathKeyEscaper escapes the backslashes and dots of a key.
+var pathKeyEscaper = strings.NewReplacer(`\`, `\\`, `.`, `\.`)
+
+// Path joins the keys of nested objects into a field, e.g. Path("address", "city") is "address.city".
+// Backslashes and dots in a key are escaped, e.g. Path("example.com", "visits") is `example\.com.visits`.
+//
+// Fields are dotted paths everywhere a field is accepted. jsonbox can not address a key containing a dot,
+// so such fields are rejected by the filters and sorts sent to jsonbox and only work client-side,
+// e.g. in Compare, In, Regex, HybridQuery.OrderBy and HybridQuery.Select.
+func Path(keys ...string) string {
+	escaped := make([]string, 0, len(keys))
+	for _, key := range keys {
+		escaped = append(escaped, pathKeyEscaper.Replace(key))
+	}
+	return strings.Join(escaped, ".")
+}
+
+// Split the field into the keys of nested objects, `\.` and `\\` are a literal dot and backslash
+func parsePath(field string) ([]string, error) {
+	keys := make([]string, 0)
+	var key strings.Builder
+	for i := 0; i < len(field); i++ {
+		switch field[i] {
+		case '\\':
+			if i+1 >= len(field) || (field[i+1] != '.' && field[i+1] != '\\') {
+				return nil, fmt.Errorf("%w: invalid escape in field %q", ErrInvalidQuery, field)
+			}
+			i++
+			key.WriteByte(field[i])
+		case '.':
+			keys = append(keys, key.String())
+			key.Reset()
+		default:
+			key.WriteByte(field[i])
+		}
+	}
+	keys = append(keys, key.String())
+	for _, key := range keys {
+		if key == "" {
+			return nil, fmt.Errorf("%w: empty key in field %q", ErrInvalidQuery, field)
+		}
+	}
+	return keys, nil
+}
+
+// Split the validated field into the keys of nested objects
+func splitPath(field string) []string {
+	keys, err := parsePath(field)
+	if err != nil {
+		return strings.Split(field, ".")
+	}
+	return keys
+}
+
+// Validate the field can be evaluated client-side
+func validatePath(field string) error {
+	_, err := parsePath(field)
+	return err
+}
+
+// The field in the form jsonbox addresses it, keys containing a dot can not be addressed
+func serverField(field string) (string, error) {
+	keys, err := parsePath(field)
+	if err != nil {
+		return "", err
+	}
+	for _, key := range keys {
+		if strings.Contains(key, ".") {
+			return "", fmt.Errorf("%w: key %q of %q contains '.', jsonbox can not address it", ErrInvalidQuery, key, field)
+		}
+	}
+	return strings.Join(keys, "."), nil
+}
+
+// The field of the form jsonbox addresses, the inverse of serverField
+func fieldFromServer(field string) string {
+	return strings.ReplaceAll(field, `\`, `\\`)
+}
+
+// The validated field in the form jsonbox addresses it
+func wireField(field string) string {
+	if converted, err := serverField(field); err == nil {
+		return converted
+	}
+	return field
+}
+
+// The sort parameter in the form jsonbox addresses it, e.g. `-a\\b` -> `-a\b`
+func wireSort(sort string) string {
+	if strings.HasPrefix(sort, "-") {
+		return "-" + wireField(sort[1:])
+	}
+	return wireField(sort)
+}
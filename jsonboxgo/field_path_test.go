@@ -0,0 +1,168 @@
+package jsonboxgo
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestPath(t *testing.T) {
+	// test cases
+	testCases := map[string]struct {
+		InputKeys    []string
+		ExpectedPath string
+		ExpectedKeys []string
+	}{
+		"Single key.":        {InputKeys: []string{"name"}, ExpectedPath: "name", ExpectedKeys: []string{"name"}},
+		"Nested keys.":       {InputKeys: []string{"address", "city"}, ExpectedPath: "address.city", ExpectedKeys: []string{"address", "city"}},
+		"Key with dot.":      {InputKeys: []string{"example.com", "visits"}, ExpectedPath: `example\.com.visits`, ExpectedKeys: []string{"example.com", "visits"}},
+		"Key with backslash": {InputKeys: []string{`a\b`, `c\`}, ExpectedPath: `a\\b.c\\`, ExpectedKeys: []string{`a\b`, `c\`}},
+	}
+
+	// run
+	for testCase, param := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			actual := Path(param.InputKeys...)
+			expected := param.ExpectedPath
+			if actual != expected {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+			}
+			actualKeys, err := parsePath(actual)
+			if err != nil || !reflect.DeepEqual(actualKeys, param.ExpectedKeys) {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actualKeys, actualKeys, param.ExpectedKeys, param.ExpectedKeys)
+			}
+		})
+	}
+}
+
+func TestFieldPathQuery(t *testing.T) {
+	// test cases
+	testCases := map[string]struct {
+		InputQuery    Querier
+		ExpectedQuery string
+		ExpectedError error
+	}{
+		"Nested field filter.": {
+			InputQuery:    NewQueryBuilder().AndEqual(Path("address", "city"), "Tokyo").AndGreaterThanInt("stats.visits.total", 10),
+			ExpectedQuery: "?q=address.city:=Tokyo,stats.visits.total:>10",
+		},
+		"Nested field sort.": {
+			InputQuery:    NewQueryBuilder().SortDesc(Path("address", "zip")),
+			ExpectedQuery: "?sort=-address.zip",
+		},
+		"Nested field of Query.": {
+			InputQuery:    NewQuery().SortAsc("address.zip").AndStartsWith("address.city", "To"),
+			ExpectedQuery: "?sort=address.zip&q=address.city:To*",
+		},
+		"Key with backslash.": {
+			InputQuery:    NewQueryBuilder().SortAsc(Path(`a\b`)).AndEqual(Path(`a\b`, "c"), "x"),
+			ExpectedQuery: "?sort=a%5Cb&q=a%5Cb.c:=x",
+		},
+		"Key with dot is rejected.": {
+			InputQuery:    NewQueryBuilder().AndEqual(Path("example.com", "visits"), "1"),
+			ExpectedQuery: "?",
+			ExpectedError: ErrInvalidQuery,
+		},
+		"Sort by key with dot is rejected.": {
+			InputQuery:    NewQuery().SortAsc(Path("example.com")),
+			ExpectedQuery: "?",
+			ExpectedError: ErrInvalidQuery,
+		},
+		"Empty key.": {
+			InputQuery:    NewQueryBuilder().AndEqual("address..city", "Tokyo"),
+			ExpectedQuery: "?",
+			ExpectedError: ErrInvalidQuery,
+		},
+		"Trailing dot.": {
+			InputQuery:    NewQueryBuilder().AndEqualInt("address.", 1),
+			ExpectedQuery: "?",
+			ExpectedError: ErrInvalidQuery,
+		},
+		"Invalid escape.": {
+			InputQuery:    NewQueryBuilder().SortAsc(`a\b`),
+			ExpectedQuery: "?",
+			ExpectedError: ErrInvalidQuery,
+		},
+	}
+
+	// run
+	for testCase, param := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			actual := param.InputQuery.Build()
+			expected := param.ExpectedQuery
+			if actual != expected {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+			}
+			err := param.InputQuery.Err()
+			if !errors.Is(err, param.ExpectedError) || (err == nil) != (param.ExpectedError == nil) {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", err, err, param.ExpectedError, param.ExpectedError)
+			}
+			if err != nil {
+				return
+			}
+			parsed, err := ParseQuery(actual)
+			if err != nil || parsed.Build() != actual {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", parsed, parsed, actual, actual)
+			}
+		})
+	}
+}
+
+func TestFieldPathClientSide(t *testing.T) {
+	record := `{"_id":"1","example.com":{"visits":3,"owner":"taro"},"address":{"city":"Tokyo"}}`
+	dotted := Path("example.com", "visits")
+	// test cases
+	testCases := map[string]struct {
+		InputQuery         *HybridQuery
+		ExpectedRecords    []string
+		ExpectedRequestUrl string
+	}{
+		"Compare key with dot.": {
+			InputQuery:         NewHybridQuery(nil).Where(Compare(dotted, OpGreaterThan, 2)),
+			ExpectedRecords:    []string{record},
+			ExpectedRequestUrl: "https://test.com/box_test/users?offset=0&limit=100",
+		},
+		"In key with dot.": {
+			InputQuery:         NewHybridQuery(nil).Where(In(dotted, 1, 2)),
+			ExpectedRecords:    []string{},
+			ExpectedRequestUrl: "https://test.com/box_test/users?offset=0&limit=100",
+		},
+		"Nested field is sent to jsonbox.": {
+			InputQuery:         NewHybridQuery(nil).Where(In("address.city", "Tokyo")).Select("address.city"),
+			ExpectedRecords:    []string{`{"address":{"city":"Tokyo"}}`},
+			ExpectedRequestUrl: "https://test.com/box_test/users?limit=20&q=address.city:=Tokyo",
+		},
+		"Select and sort by key with dot.": {
+			InputQuery:         NewHybridQuery(nil).OrderBy(Desc(dotted)).Select(Path("example.com", "owner")),
+			ExpectedRecords:    []string{`{"example.com":{"owner":"taro"}}`},
+			ExpectedRequestUrl: "https://test.com/box_test/users?offset=0&limit=100",
+		},
+	}
+
+	// run
+	for testCase, param := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			requests := make([]*http.Request, 0)
+			responses := []TestResponse{{StatusCode: 200, Body: "[" + record + "]"}, {StatusCode: 200, Body: "[]"}}
+			client := NewTestJsonboxClient(CreateNewSequenceTestClient(responses, &requests))
+			records, err := param.InputQuery.Fetch(client, "users")
+			if err != nil {
+				t.Fatalf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", err, err, nil, nil)
+			}
+			actual := make([]string, 0)
+			for _, record := range records {
+				actual = append(actual, string(record))
+			}
+			expected := param.ExpectedRecords
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+			}
+			actualUrl := requests[0].URL.String()
+			expectedUrl := param.ExpectedRequestUrl
+			if actualUrl != expectedUrl {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actualUrl, actualUrl, expectedUrl, expectedUrl)
+			}
+		})
+	}
+}
@@ -0,0 +1,446 @@
+package jsonboxgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Predicate is a condition of a HybridQuery.
+// Predicates jsonbox can express are sent in "q=", the others are evaluated client-side.
+type Predicate struct {
+	match  func(record map[string]interface{}) bool
+	filter *filter
+	err    error
+}
+
+// Match reports whether the record satisfies the predicate.
+func (p Predicate) Match(record map[string]interface{}) bool {
+	return p.err == nil && p.match != nil && p.match(record)
+}
+
+// Err returns the error occurred while creating the predicate.
+func (p Predicate) Err() error {
+	return p.err
+}
+
+// Compare is a jsonbox filter such as "age:>=40", it accepts the values of QueryBuilder.AndWhere.
+// Values jsonbox can not represent in "q=", e.g. containing ",", are compared client-side.
+func Compare(field string, operator Operator, value interface{}) Predicate {
+	var formatted string
+	switch operator {
+	case OpMatch:
+		pattern, ok := value.(string)
+		if !ok {
+			return Predicate{err: fmt.Errorf("%w: operator %q can not be used with %T value of %q", ErrInvalidQuery, operator, value, field)}
+		}
+		if err := validatePattern(field, pattern); err != nil {
+			return Predicate{err: err}
+		}
+		formatted = pattern
+	case OpEqual, OpGreaterThan, OpGreaterThanOrEqual, OpLessThan, OpLessThanOrEqual:
+		var err error
+		if formatted, err = formatValue(field, operator, value); err != nil {
+			return Predicate{err: err}
+		}
+	default:
+		return Predicate{err: fmt.Errorf("%w: unknown operator %q", ErrInvalidQuery, operator)}
+	}
+	if err := validatePath(field); err != nil {
+		return Predicate{err: err}
+	}
+	f := filter{field: field, operator: string(operator), value: formatted}
+	p := Predicate{match: newCondition(f).match}
+	if validateFilter(field, string(operator), formatted) == nil {
+		p.filter = &f
+	}
+	return p
+}
+
+// In matches records whose field equals one of the values, or an array field containing one of them.
+// Strings, bools, numbers, time.Time and nil are compared by type, so "40" does not equal 40.
+func In(field string, values ...interface{}) Predicate {
+	if err := validatePath(field); err != nil {
+		return Predicate{err: err}
+	}
+	comparables := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		comparable, err := comparableValue(field, value)
+		if err != nil {
+			return Predicate{err: err}
+		}
+		comparables = append(comparables, comparable)
+	}
+	path := splitPath(field)
+	p := Predicate{match: func(record map[string]interface{}) bool {
+		return anyValue(lookupPath(record, path), func(value interface{}) bool {
+			for _, comparable := range comparables {
+				if compareTyped(value, comparable) == 0 {
+					return true
+				}
+			}
+			return false
+		})
+	}}
+	if len(values) == 1 {
+		p.filter = equalFilter(field, values[0], comparables[0])
+	}
+	return p
+}
+
+// NotIn matches records whose field is missing or equals none of the values.
+func NotIn(field string, values ...interface{}) Predicate {
+	return Not(In(field, values...))
+}
+
+// NotEqual matches records whose field is missing or does not equal the value.
+func NotEqual(field string, value interface{}) Predicate {
+	return Not(In(field, value))
+}
+
+// Regex matches records whose string field matches the regular expression (RE2 syntax).
+func Regex(field string, expression string) Predicate {
+	if err := validatePath(field); err != nil {
+		return Predicate{err: err}
+	}
+	compiled, err := regexp.Compile(expression)
+	if err != nil {
+		return Predicate{err: fmt.Errorf("%w: invalid regular expression of %q: %v", ErrInvalidQuery, field, err)}
+	}
+	path := splitPath(field)
+	return Predicate{match: func(record map[string]interface{}) bool {
+		return anyValue(lookupPath(record, path), func(value interface{}) bool {
+			s, ok := value.(string)
+			return ok && compiled.MatchString(s)
+		})
+	}}
+}
+
+// Or matches records satisfying any of the predicates.
+func Or(predicates ...Predicate) Predicate {
+	if err := predicatesErr(predicates); err != nil {
+		return Predicate{err: err}
+	}
+	return Predicate{match: func(record map[string]interface{}) bool {
+		for _, p := range predicates {
+			if p.Match(record) {
+				return true
+			}
+		}
+		return false
+	}}
+}
+
+// And matches records satisfying all of the predicates, e.g. inside Or.
+func And(predicates ...Predicate) Predicate {
+	if err := predicatesErr(predicates); err != nil {
+		return Predicate{err: err}
+	}
+	return Predicate{match: func(record map[string]interface{}) bool {
+		for _, p := range predicates {
+			if !p.Match(record) {
+				return false
+			}
+		}
+		return true
+	}}
+}
+
+// Not matches records not satisfying the predicate.
+func Not(predicate Predicate) Predicate {
+	if predicate.err != nil {
+		return Predicate{err: predicate.err}
+	}
+	return Predicate{match: func(record map[string]interface{}) bool {
+		return !predicate.Match(record)
+	}}
+}
+
+// HybridQuery is a query mixing filters jsonbox can express with client-side predicates.
+// The filters are sent in "q=" and the rest are applied while paging through the results,
+// then the offset and limit are applied to the filtered records.
+type HybridQuery struct {
+	server       *DefaultQueryBuilder
+	predicates   []Predicate
+	offset       int
+	limit        int
+	orderBy      sortKeys
+	serverSorted bool
+	fields       [][]string
+	pageSize     int
+	err          error
+}
+
+// Create new HybridQuery, the filters and sort of the base query are sent to jsonbox as they are
+// and its offset and limit are applied to the filtered records. base can be nil.
+func NewHybridQuery(base Querier) *HybridQuery {
+	h := &HybridQuery{server: NewQueryBuilder().(*DefaultQueryBuilder), limit: ServerDefaultLimit, pageSize: DefaultPageSize}
+	if base == nil {
+		return h
+	}
+	if err := base.Err(); err != nil {
+		return h.fail(err)
+	}
+	parsed, err := parseQueryString(base.Build())
+	if err != nil {
+		return h.fail(err)
+	}
+	h.server.filters = parsed.filters
+	if sort := parsed.param("sort"); sort != "" {
+		h.server.setQuery("sort", sort)
+	}
+	if offset := parsed.param("offset"); offset != "" {
+		h.offset, _ = strconv.Atoi(offset)
+	}
+	if limit := parsed.param("limit"); limit != "" {
+		h.limit, _ = strconv.Atoi(limit)
+	}
+	return h
+}
+
+// Where adds the predicate, it is sent to jsonbox when jsonbox can express it.
+func (h *HybridQuery) Where(predicates ...Predicate) *HybridQuery {
+	for _, p := range predicates {
+		if p.err != nil {
+			return h.fail(p.err)
+		}
+		if p.filter != nil {
+			h.server.filters = append(h.server.filters, *p.filter)
+		} else {
+			h.predicates = append(h.predicates, p)
+		}
+	}
+	return h
+}
+
+// Offset skips the filtered records.
+func (h *HybridQuery) Offset(offset int) *HybridQuery {
+	if offset < 0 {
+		return h.fail(fmt.Errorf("%w: negative offset %d", ErrInvalidQuery, offset))
+	}
+	h.offset = offset
+	return h
+}
+
+// Limit is the number of filtered records to fetch, ServerDefaultLimit by default.
+func (h *HybridQuery) Limit(limit int) *HybridQuery {
+	if limit < 0 {
+		return h.fail(fmt.Errorf("%w: negative limit %d", ErrInvalidQuery, limit))
+	}
+	h.limit = limit
+	return h
+}
+
+// PageSize is the number of records read from jsonbox at once, DefaultPageSize by default.
+func (h *HybridQuery) PageSize(pageSize int) *HybridQuery {
+	if pageSize <= 0 {
+		return h.fail(fmt.Errorf("%w: page size must be positive, got %d", ErrInvalidQuery, pageSize))
+	}
+	h.pageSize = pageSize
+	return h
+}
+
+// ServerQuery returns the part of the query sent to jsonbox, it has no offset and limit.
+func (h *HybridQuery) ServerQuery() Querier {
+	return h.server
+}
+
+// Match reports whether the record satisfies the filters and predicates.
+func (h *HybridQuery) Match(record map[string]interface{}) bool {
+	for _, f := range h.server.filters {
+		if !newCondition(f).match(record) {
+			return false
+		}
+	}
+	return h.matchPredicates(record)
+}
+
+// Evaluate the client-side predicates only, jsonbox has already applied the filters
+func (h *HybridQuery) matchPredicates(record map[string]interface{}) bool {
+	for _, p := range h.predicates {
+		if !p.Match(record) {
+			return false
+		}
+	}
+	return true
+}
+
+// Err returns the first error occurred while building the query.
+func (h *HybridQuery) Err() error {
+	return h.err
+}
+
+// Fetch reads the records of the collection matching the query.
+func (h *HybridQuery) Fetch(client Client, collection string) ([][]byte, error) {
+	return h.FetchContext(context.Background(), client, collection)
+}
+
+// FetchContext reads the records of the collection matching the query.
+// When every condition is sent to jsonbox, at most one sort key is given and the limit is at most ServerMaxLimit,
+// the offset and limit are sent too and a single request is made.
+// Otherwise pages are read until the limit is filled or the records run out.
+func (h *HybridQuery) FetchContext(ctx context.Context, client Client, collection string) ([][]byte, error) {
+	if h.err != nil {
+		return nil, h.err
+	}
+	if h.limit == 0 {
+		return make([][]byte, 0), nil
+	}
+	var records [][]byte
+	var err error
+	if len(h.predicates) == 0 && !h.sortsClientSide() && h.limit <= ServerMaxLimit {
+		records, err = h.fetchServerSide(ctx, client, collection)
+	} else {
+		records, err = h.fetchPages(ctx, client, collection)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return h.project(records)
+}
+
+// Read pages sorted by the first sort key, the other keys are applied after the records of the window are read
+func (h *HybridQuery) fetchPages(ctx context.Context, client Client, collection string) ([][]byte, error) {
+	window := h.offset + h.limit
+	if window < 0 {
+		window = math.MaxInt
+	}
+	matched := make([]sortableRecord, 0)
+	it := client.IterateContext(ctx, collection, h.server, h.pageSize)
+	for it.Next() {
+		record, err := decodeRecord(it.Record())
+		if err != nil {
+			return nil, err
+		}
+		if !h.matchPredicates(record) {
+			continue
+		}
+		if h.sortsClientSide() && h.serverSorted && len(matched) >= window {
+			// the records after the window can only tie with its last record by the first key
+			if h.orderBy[:1].compare(record, matched[window-1].decoded) != 0 {
+				break
+			}
+		}
+		matched = append(matched, sortableRecord{raw: it.Record(), decoded: record})
+		if !h.sortsClientSide() && len(matched) >= window {
+			break
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	if h.sortsClientSide() {
+		sort.SliceStable(matched, func(i, j int) bool {
+			return h.orderBy.compare(matched[i].decoded, matched[j].decoded) < 0
+		})
+	}
+	records := make([][]byte, 0)
+	for i := h.offset; i < len(matched) && i < window; i++ {
+		records = append(records, matched[i].raw)
+	}
+	return records, nil
+}
+
+// Read the page at once when jsonbox can filter everything
+func (h *HybridQuery) fetchServerSide(ctx context.Context, client Client, collection string) ([][]byte, error) {
+	query := NewQueryBuilder().(*DefaultQueryBuilder)
+	query.queries = append(query.queries, h.server.queries...)
+	query.filters = h.server.filters
+	if h.offset > 0 {
+		query.Offset(h.offset)
+	}
+	query.Limit(h.limit)
+	body, err := client.ReadByQueryContext(ctx, collection, query)
+	if err != nil {
+		return nil, err
+	}
+	raw := make([]json.RawMessage, 0)
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, err
+	}
+	records := make([][]byte, 0, len(raw))
+	for _, record := range raw {
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func (h *HybridQuery) fail(err error) *HybridQuery {
+	if h.err == nil {
+		h.err = err
+	}
+	return h
+}
+
+// Convert the value to the type it is compared by
+func comparableValue(field string, value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case nil, string, bool:
+		return v, nil
+	case time.Time:
+		return v.UTC().Format(TimeFormat), nil
+	case json.Number, float32, float64:
+		formatted, err := formatValue(field, OpEqual, v)
+		if err != nil {
+			return nil, err
+		}
+		number, _ := strconv.ParseFloat(formatted, 64)
+		return number, nil
+	}
+	normalized := normalizeValue(value)
+	if _, ok := normalized.(float64); !ok {
+		return nil, fmt.Errorf("%w: unsupported value type %T of %q", ErrInvalidQuery, value, field)
+	}
+	return normalized, nil
+}
+
+// The equality filter of the value when jsonbox compares it the same way
+func equalFilter(field string, value interface{}, comparable interface{}) *filter {
+	formatted, err := formatValue(field, OpEqual, value)
+	if err != nil || validateFilter(field, string(OpEqual), formatted) != nil {
+		return nil
+	}
+	if compareTyped(parseComparable(formatted, true), comparable) != 0 {
+		return nil
+	}
+	return &filter{field: field, operator: string(OpEqual), value: formatted}
+}
+
+// Compare values of the same type, values of different types are never equal
+func compareTyped(value interface{}, comparable interface{}) int {
+	value = normalizeValue(value)
+	if typeOrder(value) != typeOrder(comparable) {
+		return 1
+	}
+	return compareValues(value, comparable)
+}
+
+// Whether any value or any element of an array value satisfies the function
+func anyValue(values []interface{}, satisfy func(interface{}) bool) bool {
+	for _, value := range values {
+		if elements, ok := value.([]interface{}); ok {
+			if anyValue(elements, satisfy) {
+				return true
+			}
+			continue
+		}
+		if satisfy(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func predicatesErr(predicates []Predicate) error {
+	for _, p := range predicates {
+		if p.err != nil {
+			return p.err
+		}
+	}
+	return nil
+}
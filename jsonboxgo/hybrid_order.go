@@ -0,0 +1,181 @@
+package jsonboxgo
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// SortKey is a key of HybridQuery.OrderBy.
+type SortKey struct {
+	Field      string
+	Descending bool
+}
+
+// Asc sorts by the field in ascending order.
+func Asc(field string) SortKey {
+	return SortKey{Field: field}
+}
+
+// Desc sorts by the field in descending order.
+func Desc(field string) SortKey {
+	return SortKey{Field: field, Descending: true}
+}
+
+type sortKeys []SortKey
+
+// A record with its decoded form for sorting
+type sortableRecord struct {
+	raw     []byte
+	decoded map[string]interface{}
+}
+
+// OrderBy sorts the records by the keys in order, it replaces the sort of the base query.
+// jsonbox sorts by the first key and the other keys are applied client-side,
+// so every record tying with the last record of offset+limit by the first key is read.
+// When jsonbox can not sort by the first key, e.g. its key contains a dot, every matching record is read.
+func (h *HybridQuery) OrderBy(keys ...SortKey) *HybridQuery {
+	for _, key := range keys {
+		if err := validatePath(key.Field); err != nil {
+			return h.fail(err)
+		}
+	}
+	h.orderBy = keys
+	h.server.queries = removeQueryParam(h.server.queries, "sort")
+	h.serverSorted = len(keys) > 0 && validateField(keys[0].Field) == nil
+	if !h.serverSorted {
+		return h
+	}
+	if keys[0].Descending {
+		h.server.SortDesc(keys[0].Field)
+	} else {
+		h.server.SortAsc(keys[0].Field)
+	}
+	return h
+}
+
+// Select trims the fetched records to the fields, dotted fields such as "user.name" select nested fields.
+// Fields missing in a record are omitted, all fields are returned when no field is selected.
+func (h *HybridQuery) Select(fields ...string) *HybridQuery {
+	h.fields = make([][]string, 0, len(fields))
+	for _, field := range fields {
+		if err := validatePath(field); err != nil {
+			return h.fail(err)
+		}
+		h.fields = append(h.fields, splitPath(field))
+	}
+	// a field selected as a whole covers its nested fields
+	covered := make([][]string, 0, len(h.fields))
+	for _, path := range h.fields {
+		if !hasPrefixPath(h.fields, path) {
+			covered = append(covered, path)
+		}
+	}
+	h.fields = covered
+	return h
+}
+
+// Whether a shorter path of the paths is a prefix of the path
+func hasPrefixPath(paths [][]string, path []string) bool {
+	for _, prefix := range paths {
+		if len(prefix) < len(path) && reflect.DeepEqual(prefix, path[:len(prefix)]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Whether the records are sorted client-side after reading
+func (h *HybridQuery) sortsClientSide() bool {
+	return len(h.orderBy) > 1 || (len(h.orderBy) == 1 && !h.serverSorted)
+}
+
+// Compare the records by the keys, -1, 0 or 1
+func (keys sortKeys) compare(a map[string]interface{}, b map[string]interface{}) int {
+	for _, key := range keys {
+		path := splitPath(key.Field)
+		result := compareValues(sortValue(a, path), sortValue(b, path))
+		if key.Descending {
+			result = -result
+		}
+		if result != 0 {
+			return result
+		}
+	}
+	return 0
+}
+
+// Trim the records to the selected fields
+func (h *HybridQuery) project(records [][]byte) ([][]byte, error) {
+	if len(h.fields) == 0 {
+		return records, nil
+	}
+	projected := make([][]byte, 0, len(records))
+	for _, record := range records {
+		decoded, err := decodeRecord(record)
+		if err != nil {
+			return nil, err
+		}
+		selected := make(map[string]interface{})
+		for _, path := range h.fields {
+			selectPath(selected, decoded, path)
+		}
+		encoded, err := json.Marshal(selected)
+		if err != nil {
+			return nil, err
+		}
+		projected = append(projected, encoded)
+	}
+	return projected, nil
+}
+
+// Copy the value at the path of the source into the destination, the objects of an array are selected element by element
+func selectPath(destination map[string]interface{}, source map[string]interface{}, path []string) {
+	value, ok := source[path[0]]
+	if !ok {
+		return
+	}
+	if len(path) == 1 {
+		destination[path[0]] = value
+		return
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		child, _ := destination[path[0]].(map[string]interface{})
+		if child == nil {
+			child = make(map[string]interface{})
+		}
+		selectPath(child, v, path[1:])
+		if len(child) > 0 {
+			destination[path[0]] = child
+		}
+	case []interface{}:
+		objects := make([]map[string]interface{}, 0, len(v))
+		for _, element := range v {
+			if object, ok := element.(map[string]interface{}); ok {
+				objects = append(objects, object)
+			}
+		}
+		children, _ := destination[path[0]].([]interface{})
+		if children == nil {
+			children = make([]interface{}, len(objects))
+			for i := range children {
+				children[i] = make(map[string]interface{})
+			}
+		}
+		for i, object := range objects {
+			selectPath(children[i].(map[string]interface{}), object, path[1:])
+		}
+		destination[path[0]] = children
+	}
+}
+
+// Remove the parameter from the query parameters
+func removeQueryParam(queries []queryParam, name string) []queryParam {
+	kept := make([]queryParam, 0, len(queries))
+	for _, query := range queries {
+		if query.name != name {
+			kept = append(kept, query)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,133 @@
+package jsonboxgo
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestHybridQueryOrderBy(t *testing.T) {
+	// test cases
+	testCases := map[string]struct {
+		InputQuery          *HybridQuery
+		InputResponses      []TestResponse
+		ExpectedRecords     []string
+		ExpectedRequestUrls []string
+	}{
+		"Single key is sent to jsonbox.": {
+			InputQuery: NewHybridQuery(NewQueryBuilder().SortAsc("name")).OrderBy(Desc("age")).Limit(2),
+			InputResponses: []TestResponse{
+				{StatusCode: 200, Body: `[{"_id":"1"},{"_id":"2"}]`},
+			},
+			ExpectedRecords:     []string{`{"_id":"1"}`, `{"_id":"2"}`},
+			ExpectedRequestUrls: []string{"https://test.com/box_test/users?sort=-age&limit=2"},
+		},
+		"Secondary key is applied client-side.": {
+			InputQuery: NewHybridQuery(nil).OrderBy(Asc("age"), Desc("name")).Offset(1).Limit(2).PageSize(2),
+			InputResponses: []TestResponse{
+				{StatusCode: 200, Body: `[{"_id":"1","age":10,"name":"a"},{"_id":"2","age":20,"name":"a"}]`},
+				{StatusCode: 200, Body: `[{"_id":"3","age":20,"name":"c"},{"_id":"4","age":20,"name":"b"}]`},
+				{StatusCode: 200, Body: `[{"_id":"5","age":30,"name":"z"},{"_id":"6","age":40,"name":"z"}]`},
+			},
+			ExpectedRecords: []string{
+				`{"_id":"3","age":20,"name":"c"}`,
+				`{"_id":"4","age":20,"name":"b"}`,
+			},
+			ExpectedRequestUrls: []string{
+				"https://test.com/box_test/users?offset=0&limit=2&sort=age",
+				"https://test.com/box_test/users?offset=2&limit=2&sort=age",
+				"https://test.com/box_test/users?offset=4&limit=2&sort=age",
+			},
+		},
+		"Records run out.": {
+			InputQuery: NewHybridQuery(nil).OrderBy(Desc("score"), Asc("name")).Where(NotEqual("hidden", true)),
+			InputResponses: []TestResponse{
+				{StatusCode: 200, Body: `[{"_id":"1","score":2,"name":"b"},{"_id":"2","score":2,"name":"a","hidden":true},{"_id":"3","score":2,"name":"a"}]`},
+				{StatusCode: 200, Body: `[]`},
+			},
+			ExpectedRecords: []string{
+				`{"_id":"3","score":2,"name":"a"}`,
+				`{"_id":"1","score":2,"name":"b"}`,
+			},
+			ExpectedRequestUrls: []string{
+				"https://test.com/box_test/users?offset=0&limit=100&sort=-score",
+				"https://test.com/box_test/users?offset=3&limit=100&sort=-score",
+			},
+		},
+	}
+
+	// run
+	for testCase, param := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			requests := make([]*http.Request, 0)
+			client := NewTestJsonboxClient(CreateNewSequenceTestClient(param.InputResponses, &requests))
+			records, err := param.InputQuery.Fetch(client, "users")
+			if err != nil {
+				t.Fatalf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", err, err, nil, nil)
+			}
+			actual := make([]string, 0)
+			for _, record := range records {
+				actual = append(actual, string(record))
+			}
+			expected := param.ExpectedRecords
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+			}
+			actualUrls := make([]string, 0)
+			for _, request := range requests {
+				actualUrls = append(actualUrls, request.URL.String())
+			}
+			expectedUrls := param.ExpectedRequestUrls
+			if !reflect.DeepEqual(actualUrls, expectedUrls) {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actualUrls, actualUrls, expectedUrls, expectedUrls)
+			}
+		})
+	}
+}
+
+func TestHybridQuerySelect(t *testing.T) {
+	record := `{"_id":"1","name":"taro","age":40,"user":{"country":"JP","address":{"city":"Tokyo","zip":"100"}},"items":[{"name":"a","price":100},{"name":"b"},3]}`
+	// test cases
+	testCases := map[string]struct {
+		InputFields    []string
+		ExpectedRecord string
+	}{
+		"No fields.": {
+			InputFields:    []string{},
+			ExpectedRecord: record,
+		},
+		"Top level fields.": {
+			InputFields:    []string{"_id", "name", "missing"},
+			ExpectedRecord: `{"_id":"1","name":"taro"}`,
+		},
+		"Nested fields.": {
+			InputFields:    []string{"user.address.city", "user.country", "user.missing.field"},
+			ExpectedRecord: `{"user":{"address":{"city":"Tokyo"},"country":"JP"}}`,
+		},
+		"Fields of array elements.": {
+			InputFields:    []string{"items.price", "items.name"},
+			ExpectedRecord: `{"items":[{"name":"a","price":100},{"name":"b"}]}`,
+		},
+		"Whole field covers nested fields.": {
+			InputFields:    []string{"items.price", "items", "age"},
+			ExpectedRecord: `{"age":40,"items":[{"name":"a","price":100},{"name":"b"},3]}`,
+		},
+	}
+
+	// run
+	for testCase, param := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			requests := make([]*http.Request, 0)
+			client := NewTestJsonboxClient(CreateNewSequenceTestClient([]TestResponse{{StatusCode: 200, Body: "[" + record + "]"}}, &requests))
+			records, err := NewHybridQuery(nil).Select(param.InputFields...).Fetch(client, "users")
+			if err != nil || len(records) != 1 {
+				t.Fatalf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", err, err, nil, nil)
+			}
+			actual := string(records[0])
+			expected := param.ExpectedRecord
+			if actual != expected {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package jsonboxgo
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestHybridQueryFetch(t *testing.T) {
+	// test cases
+	testCases := map[string]struct {
+		InputQuery          *HybridQuery
+		InputResponses      []TestResponse
+		ExpectedRecords     []string
+		ExpectedRequestUrls []string
+		ExpectedError       error
+	}{
+		"Everything is sent to jsonbox.": {
+			InputQuery: NewHybridQuery(NewQueryBuilder().SortDesc("age").Offset(1).Limit(2)).
+				Where(Compare("age", OpGreaterThanOrEqual, 20), In("country", "JP")),
+			InputResponses: []TestResponse{
+				{StatusCode: 200, Body: `[{"_id":"id002"},{"_id":"id003"}]`},
+			},
+			ExpectedRecords: []string{`{"_id":"id002"}`, `{"_id":"id003"}`},
+			ExpectedRequestUrls: []string{
+				"https://test.com/box_test/users?sort=-age&offset=1&limit=2&q=age:>=20,country:=JP",
+			},
+		},
+		"In is filtered client-side.": {
+			InputQuery: NewHybridQuery(NewQueryBuilder().AndEqual("active", "true")).
+				Where(In("country", "JP", "US")).PageSize(2).Limit(2),
+			InputResponses: []TestResponse{
+				{StatusCode: 200, Body: `[{"_id":"id001","country":"FR"},{"_id":"id002","country":"JP"}]`},
+				{StatusCode: 200, Body: `[{"_id":"id003","country":"DE"},{"_id":"id004","country":"US"}]`},
+			},
+			ExpectedRecords: []string{`{"_id":"id002","country":"JP"}`, `{"_id":"id004","country":"US"}`},
+			ExpectedRequestUrls: []string{
+				"https://test.com/box_test/users?offset=0&limit=2&q=active:=true",
+				"https://test.com/box_test/users?offset=2&limit=2&q=active:=true",
+			},
+		},
+		"Offset is applied to the filtered records.": {
+			InputQuery: NewHybridQuery(NewQuery().SortAsc("name").Offset(1).Limit(5)).
+				Where(NotEqual("status", "archived")).PageSize(3),
+			InputResponses: []TestResponse{
+				{StatusCode: 200, Body: `[{"_id":"id001"},{"_id":"id002","status":"archived"},{"_id":"id003","status":"open"}]`},
+				{StatusCode: 200, Body: `[{"_id":"id004","status":"archived"}]`},
+				{StatusCode: 200, Body: `[]`},
+			},
+			ExpectedRecords: []string{`{"_id":"id003","status":"open"}`},
+			ExpectedRequestUrls: []string{
+				"https://test.com/box_test/users?offset=0&limit=3&sort=name",
+				"https://test.com/box_test/users?offset=3&limit=3&sort=name",
+				"https://test.com/box_test/users?offset=4&limit=3&sort=name",
+			},
+		},
+		"Or, Not and Regex.": {
+			InputQuery: NewHybridQuery(nil).
+				Where(Or(Regex("name", "^ta"), Not(Compare("age", OpLessThan, 40)))).PageSize(10),
+			InputResponses: []TestResponse{
+				{StatusCode: 200, Body: `[{"_id":"id001","name":"taro","age":10},{"_id":"id002","name":"jiro","age":10},{"_id":"id003","name":"saburo","age":50}]`},
+				{StatusCode: 200, Body: `[]`},
+			},
+			ExpectedRecords: []string{`{"_id":"id001","name":"taro","age":10}`, `{"_id":"id003","name":"saburo","age":50}`},
+			ExpectedRequestUrls: []string{
+				"https://test.com/box_test/users?offset=0&limit=10",
+				"https://test.com/box_test/users?offset=3&limit=10",
+			},
+		},
+		"Value jsonbox can not express.": {
+			InputQuery: NewHybridQuery(nil).Where(Compare("memo", OpEqual, "a,b")).PageSize(10),
+			InputResponses: []TestResponse{
+				{StatusCode: 200, Body: `[{"_id":"id001","memo":"a,b"},{"_id":"id002","memo":"a"}]`},
+				{StatusCode: 200, Body: `[]`},
+			},
+			ExpectedRecords: []string{`{"_id":"id001","memo":"a,b"}`},
+			ExpectedRequestUrls: []string{
+				"https://test.com/box_test/users?offset=0&limit=10",
+				"https://test.com/box_test/users?offset=2&limit=10",
+			},
+		},
+		"Zero limit.": {
+			InputQuery:          NewHybridQuery(nil).Where(Regex("name", "a")).Limit(0),
+			ExpectedRecords:     []string{},
+			ExpectedRequestUrls: []string{},
+		},
+		"Invalid regex.": {
+			InputQuery:          NewHybridQuery(nil).Where(Or(Regex("name", "("))),
+			ExpectedRequestUrls: []string{},
+			ExpectedError:       ErrInvalidQuery,
+		},
+		"Invalid base query.": {
+			InputQuery:          NewHybridQuery(NewQueryBuilder().AndEqual("", "a")),
+			ExpectedRequestUrls: []string{},
+			ExpectedError:       ErrInvalidQuery,
+		},
+		"Unsupported value.": {
+			InputQuery:          NewHybridQuery(nil).Where(In("country", []string{"JP"})),
+			ExpectedRequestUrls: []string{},
+			ExpectedError:       ErrInvalidQuery,
+		},
+	}
+
+	// run
+	for testCase, param := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			requests := make([]*http.Request, 0)
+			client := NewTestJsonboxClient(CreateNewSequenceTestClient(param.InputResponses, &requests))
+			records, err := param.InputQuery.Fetch(client, "users")
+			if !errors.Is(err, param.ExpectedError) || (err == nil) != (param.ExpectedError == nil) {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", err, err, param.ExpectedError, param.ExpectedError)
+			}
+			if err == nil {
+				actual := make([]string, 0)
+				for _, record := range records {
+					actual = append(actual, string(record))
+				}
+				expected := param.ExpectedRecords
+				if !reflect.DeepEqual(actual, expected) {
+					t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+				}
+			}
+			actualUrls := make([]string, 0)
+			for _, request := range requests {
+				actualUrls = append(actualUrls, request.URL.String())
+			}
+			expectedUrls := param.ExpectedRequestUrls
+			if !reflect.DeepEqual(actualUrls, expectedUrls) {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actualUrls, actualUrls, expectedUrls, expectedUrls)
+			}
+		})
+	}
+}
+
+func TestPredicate(t *testing.T) {
+	record := map[string]interface{}{"name": "Taro", "age": 40, "tags": []interface{}{"a", "b"}, "user": map[string]interface{}{"country": "JP"}}
+	// test cases
+	testCases := map[string]struct {
+		InputPredicate Predicate
+		ExpectedMatch  bool
+		ExpectedPushed bool
+	}{
+		"Compare.":                 {InputPredicate: Compare("age", OpGreaterThan, 39.5), ExpectedMatch: true, ExpectedPushed: true},
+		"Compare wildcard.":        {InputPredicate: Compare("name", OpMatch, "ta*"), ExpectedMatch: true, ExpectedPushed: true},
+		"Compare with comma.":      {InputPredicate: Compare("name", OpEqual, "Taro,Jiro"), ExpectedMatch: false, ExpectedPushed: false},
+		"In number.":               {InputPredicate: In("age", 20, 40), ExpectedMatch: true},
+		"In one value is pushed.":  {InputPredicate: In("age", 40), ExpectedMatch: true, ExpectedPushed: true},
+		"In string is typed.":      {InputPredicate: In("age", "40"), ExpectedMatch: false},
+		"In string not pushed.":    {InputPredicate: In("name", "true"), ExpectedMatch: false},
+		"In array field.":          {InputPredicate: In("tags", "b", "c"), ExpectedMatch: true},
+		"In nested field.":         {InputPredicate: In("user.country", "US", "JP"), ExpectedMatch: true},
+		"NotIn.":                   {InputPredicate: NotIn("tags", "b"), ExpectedMatch: false},
+		"NotEqual missing field.":  {InputPredicate: NotEqual("status", "archived"), ExpectedMatch: true},
+		"Regex.":                   {InputPredicate: Regex("name", "(?i)^taro$"), ExpectedMatch: true},
+		"Regex on number.":         {InputPredicate: Regex("age", "40"), ExpectedMatch: false},
+		"And.":                     {InputPredicate: And(In("age", 40), Regex("name", "^J")), ExpectedMatch: false},
+		"Or.":                      {InputPredicate: Or(In("age", 41), Regex("name", "^T")), ExpectedMatch: true},
+		"Empty Or.":                {InputPredicate: Or(), ExpectedMatch: false},
+		"Not.":                     {InputPredicate: Not(Compare("age", OpEqual, 40)), ExpectedMatch: false},
+		"Zero value never matches": {InputPredicate: Predicate{}, ExpectedMatch: false},
+	}
+
+	// run
+	for testCase, param := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			actual := param.InputPredicate.Match(record)
+			expected := param.ExpectedMatch
+			if actual != expected {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+			}
+			actualPushed := param.InputPredicate.filter != nil
+			expectedPushed := param.ExpectedPushed
+			if actualPushed != expectedPushed {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actualPushed, actualPushed, expectedPushed, expectedPushed)
+			}
+		})
+	}
+}
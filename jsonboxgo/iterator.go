@@ -0,0 +1,241 @@
+package jsonboxgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// IterateOption configures Iterate.
+type IterateOption func(*Iterator)
+
+// KeysetPagination pages by "_createdOn" instead of offset, so the pages stay stable while records are inserted.
+// The records are sorted by "_createdOn" ascending, so the query must not have a sort.
+func KeysetPagination() IterateOption {
+	return func(it *Iterator) {
+		it.keyset = true
+	}
+}
+
+// Iterator reads the records of a query lazily page by page.
+//
+//	it := client.Iterate("users", jsonboxgo.NewQueryBuilder().AndEqual("country", "JP"), 100)
+//	for it.Next() {
+//		fmt.Println(string(it.Record()))
+//	}
+//	if err := it.Err(); err != nil {
+//		// handle error
+//	}
+type Iterator struct {
+	ctx      context.Context
+	fetch    func(context.Context, string) ([]byte, error)
+	filter   string
+	sort     string
+	offset   int
+	limit    int
+	pageSize int
+	keyset   bool
+
+	lastCreatedOn string
+	seenIds       map[string]bool
+	buffer        [][]byte
+	record        []byte
+	page          [][]byte
+	done          bool
+	err           error
+}
+
+// Iterate records of the query, pageSize records are read by one request
+func (c DefaultClient) Iterate(collection string, query Querier, pageSize int, opts ...IterateOption) *Iterator {
+	return c.IterateContext(context.Background(), collection, query, pageSize, opts...)
+}
+
+// Iterate records of the query with context.
+// Offset and limit of the query are the start position and the total number of the records.
+func (c DefaultClient) IterateContext(ctx context.Context, collection string, query Querier, pageSize int, opts ...IterateOption) *Iterator {
+	it := &Iterator{
+		ctx: ctx,
+		fetch: func(ctx context.Context, query string) ([]byte, error) {
+			return c.do(ctx, "GET", collection, "", query, nil)
+		},
+		pageSize: pageSize,
+		limit:    -1,
+		seenIds:  make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(it)
+	}
+	if pageSize < 1 {
+		it.err = fmt.Errorf("jsonboxgo: page size must be at least 1: %d", pageSize)
+		return it
+	}
+	if it.err = query.Err(); it.err != nil {
+		return it
+	}
+	if it.err = it.parseQuery(query.Build()); it.err != nil {
+		return it
+	}
+	if it.keyset && it.sort != "" {
+		it.err = errors.New("jsonboxgo: keyset pagination sorts by _createdOn, the query must not have a sort")
+	}
+	return it
+}
+
+// Next advances to the next record, it returns false when the records are exhausted or an error occurred.
+func (it *Iterator) Next() bool {
+	if len(it.buffer) == 0 && !it.fill() {
+		it.record = nil
+		return false
+	}
+	it.record = it.buffer[0]
+	it.buffer = it.buffer[1:]
+	return true
+}
+
+// Record returns the current record of Next.
+func (it *Iterator) Record() []byte {
+	return it.record
+}
+
+// NextPage advances to the next page, the rest of the current page is returned first if Next was called.
+func (it *Iterator) NextPage() bool {
+	if len(it.buffer) == 0 && !it.fill() {
+		it.page = nil
+		return false
+	}
+	it.page = it.buffer
+	it.buffer = nil
+	return true
+}
+
+// Page returns the current page of NextPage.
+func (it *Iterator) Page() [][]byte {
+	return it.page
+}
+
+// Err returns the error occurred during the iteration.
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// Fetch the next non-empty page into the buffer
+func (it *Iterator) fill() bool {
+	if it.err != nil || it.done {
+		return false
+	}
+	if err := it.ctx.Err(); err != nil {
+		it.err = fmt.Errorf("jsonboxgo: iteration canceled: %w", err)
+		return false
+	}
+	limit := it.pageSize
+	if it.limit >= 0 && it.limit < limit {
+		limit = it.limit
+	}
+	if limit == 0 {
+		it.done = true
+		return false
+	}
+	requestLimit := limit
+	query := pagedQuery(it.filter, it.sort, it.offset, limit)
+	if it.keyset {
+		// the yielded records of the last _createdOn come back first, read past them
+		requestLimit = limit + len(it.seenIds)
+		if requestLimit > ServerMaxLimit {
+			requestLimit = ServerMaxLimit
+		}
+		query = pagedQuery(it.keysetFilter(), "_createdOn", it.offset, requestLimit)
+	}
+	body, err := it.fetch(it.ctx, query)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	var page []json.RawMessage
+	if err := json.Unmarshal(body, &page); err != nil {
+		it.err = fmt.Errorf("jsonboxgo: json.Unmarshal() failed: %w", err)
+		return false
+	}
+	if len(page) == 0 {
+		it.done = true
+		return false
+	}
+	records := make([][]byte, 0, len(page))
+	if it.keyset {
+		// the offset is applied to the first page only
+		it.offset = 0
+		for _, record := range page {
+			if len(records) == limit {
+				// the rest is not observed, so it is read again by the next page
+				break
+			}
+			if it.observe(record) {
+				records = append(records, record)
+			}
+		}
+		if len(records) == 0 && len(page) < requestLimit {
+			// only the records of the last _createdOn were left
+			it.done = true
+			return false
+		}
+		if len(records) == 0 {
+			it.err = errors.New("jsonboxgo: keyset pagination stalled, more records share the same _createdOn than the server returns at once")
+			return false
+		}
+	} else {
+		it.offset += len(page)
+		for _, record := range page {
+			records = append(records, record)
+		}
+	}
+	if it.limit >= 0 {
+		it.limit -= len(records)
+	}
+	it.buffer = records
+	return true
+}
+
+// Remember the last _createdOn, report whether the record was not yielded yet
+func (it *Iterator) observe(record []byte) bool {
+	var meta Meta
+	_ = json.Unmarshal(record, &meta)
+	if it.seenIds[meta.Id] && meta.CreatedOn == it.lastCreatedOn {
+		return false
+	}
+	if meta.CreatedOn != it.lastCreatedOn {
+		it.lastCreatedOn = meta.CreatedOn
+		it.seenIds = make(map[string]bool)
+	}
+	it.seenIds[meta.Id] = true
+	return true
+}
+
+// Add the _createdOn condition to the filter of the query
+func (it *Iterator) keysetFilter() string {
+	if it.lastCreatedOn == "" {
+		return it.filter
+	}
+	condition := filter{field: "_createdOn", operator: ":>=", value: it.lastCreatedOn}.String()
+	if it.filter == "" {
+		return "q=" + condition
+	}
+	return it.filter + "," + condition
+}
+
+// Read offset, limit, sort and filters of the built query
+func (it *Iterator) parseQuery(query string) error {
+	builder, err := parseQueryString(query)
+	if err != nil {
+		return err
+	}
+	if offset := builder.param("offset"); offset != "" {
+		it.offset, _ = strconv.Atoi(offset)
+	}
+	if limit := builder.param("limit"); limit != "" {
+		it.limit, _ = strconv.Atoi(limit)
+	}
+	it.sort = escapeQueryValue(wireSort(builder.param("sort")))
+	it.filter = builder.filterParam()
+	return nil
+}
@@ -0,0 +1,203 @@
+package jsonboxgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestIterate(t *testing.T) {
+	// test cases
+	testCases := map[string]struct {
+		InputQuery          QueryBuilder
+		InputPageSize       int
+		InputOptions        []IterateOption
+		InputResponses      []TestResponse
+		ExpectedRecords     []string
+		ExpectedRequestUrls []string
+	}{
+		"Offset pagination.": {
+			InputQuery:    NewQueryBuilder().SortDesc("age").AndEqual("country", "JP"),
+			InputPageSize: 2,
+			InputResponses: []TestResponse{
+				{StatusCode: 200, Body: `[{"_id":"id001"},{"_id":"id002"}]`},
+				{StatusCode: 200, Body: `[{"_id":"id003"}]`},
+				{StatusCode: 200, Body: `[]`},
+			},
+			ExpectedRecords: []string{`{"_id":"id001"}`, `{"_id":"id002"}`, `{"_id":"id003"}`},
+			ExpectedRequestUrls: []string{
+				"https://test.com/box_test/users?offset=0&limit=2&sort=-age&q=country:=JP",
+				"https://test.com/box_test/users?offset=2&limit=2&sort=-age&q=country:=JP",
+				"https://test.com/box_test/users?offset=3&limit=2&sort=-age&q=country:=JP",
+			},
+		},
+		"Offset and limit of the query.": {
+			InputQuery:    NewQueryBuilder().Offset(5).Limit(3),
+			InputPageSize: 2,
+			InputResponses: []TestResponse{
+				{StatusCode: 200, Body: `[{"_id":"id001"},{"_id":"id002"}]`},
+				{StatusCode: 200, Body: `[{"_id":"id003"}]`},
+			},
+			ExpectedRecords: []string{`{"_id":"id001"}`, `{"_id":"id002"}`, `{"_id":"id003"}`},
+			ExpectedRequestUrls: []string{
+				"https://test.com/box_test/users?offset=5&limit=2",
+				"https://test.com/box_test/users?offset=7&limit=1",
+			},
+		},
+		"Keyset pagination.": {
+			InputQuery:    NewQueryBuilder().AndEqual("country", "JP"),
+			InputPageSize: 2,
+			InputOptions:  []IterateOption{KeysetPagination()},
+			InputResponses: []TestResponse{
+				{StatusCode: 200, Body: `[{"_id":"id001","_createdOn":"2020-01-01T00:00:00.000Z"},{"_id":"id002","_createdOn":"2020-01-02T00:00:00.000Z"}]`},
+				{StatusCode: 200, Body: `[{"_id":"id002","_createdOn":"2020-01-02T00:00:00.000Z"},{"_id":"id003","_createdOn":"2020-01-02T00:00:00.000Z"}]`},
+				{StatusCode: 200, Body: `[]`},
+			},
+			ExpectedRecords: []string{
+				`{"_id":"id001","_createdOn":"2020-01-01T00:00:00.000Z"}`,
+				`{"_id":"id002","_createdOn":"2020-01-02T00:00:00.000Z"}`,
+				`{"_id":"id003","_createdOn":"2020-01-02T00:00:00.000Z"}`,
+			},
+			ExpectedRequestUrls: []string{
+				"https://test.com/box_test/users?offset=0&limit=2&sort=_createdOn&q=country:=JP",
+				"https://test.com/box_test/users?offset=0&limit=3&sort=_createdOn&q=country:=JP,_createdOn:>=2020-01-02T00:00:00.000Z",
+				"https://test.com/box_test/users?offset=0&limit=4&sort=_createdOn&q=country:=JP,_createdOn:>=2020-01-02T00:00:00.000Z",
+			},
+		},
+		"Keyset pagination ends when only the last record is left.": {
+			InputQuery:    NewQueryBuilder(),
+			InputPageSize: 2,
+			InputOptions:  []IterateOption{KeysetPagination()},
+			InputResponses: []TestResponse{
+				{StatusCode: 200, Body: `[{"_id":"id001","_createdOn":"2020-01-01T00:00:00.000Z"},{"_id":"id002","_createdOn":"2020-01-02T00:00:00.000Z"}]`},
+				{StatusCode: 200, Body: `[{"_id":"id002","_createdOn":"2020-01-02T00:00:00.000Z"}]`},
+			},
+			ExpectedRecords: []string{
+				`{"_id":"id001","_createdOn":"2020-01-01T00:00:00.000Z"}`,
+				`{"_id":"id002","_createdOn":"2020-01-02T00:00:00.000Z"}`,
+			},
+			ExpectedRequestUrls: []string{
+				"https://test.com/box_test/users?offset=0&limit=2&sort=_createdOn",
+				"https://test.com/box_test/users?offset=0&limit=3&sort=_createdOn&q=_createdOn:>=2020-01-02T00:00:00.000Z",
+			},
+		},
+		"Keyset pagination with limit of the query.": {
+			InputQuery:    NewQueryBuilder().Limit(3),
+			InputPageSize: 2,
+			InputOptions:  []IterateOption{KeysetPagination()},
+			InputResponses: []TestResponse{
+				{StatusCode: 200, Body: `[{"_id":"id001","_createdOn":"2020-01-01T00:00:00.000Z"},{"_id":"id002","_createdOn":"2020-01-02T00:00:00.000Z"}]`},
+				{StatusCode: 200, Body: `[{"_id":"id002","_createdOn":"2020-01-02T00:00:00.000Z"},{"_id":"id003","_createdOn":"2020-01-03T00:00:00.000Z"}]`},
+			},
+			ExpectedRecords: []string{
+				`{"_id":"id001","_createdOn":"2020-01-01T00:00:00.000Z"}`,
+				`{"_id":"id002","_createdOn":"2020-01-02T00:00:00.000Z"}`,
+				`{"_id":"id003","_createdOn":"2020-01-03T00:00:00.000Z"}`,
+			},
+			ExpectedRequestUrls: []string{
+				"https://test.com/box_test/users?offset=0&limit=2&sort=_createdOn",
+				"https://test.com/box_test/users?offset=0&limit=2&sort=_createdOn&q=_createdOn:>=2020-01-02T00:00:00.000Z",
+			},
+		},
+	}
+
+	// run
+	for testCase, param := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			requests := make([]*http.Request, 0)
+			client := NewTestJsonboxClient(CreateNewSequenceTestClient(param.InputResponses, &requests))
+			it := client.Iterate("users", param.InputQuery, param.InputPageSize, param.InputOptions...)
+			actual := make([]string, 0)
+			for it.Next() {
+				actual = append(actual, string(it.Record()))
+			}
+			if err := it.Err(); err != nil {
+				t.Fatalf("  Failed: err -> %v(%T)\n", err, err)
+			}
+			expected := param.ExpectedRecords
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+			}
+			actualUrls := make([]string, 0)
+			for _, req := range requests {
+				actualUrls = append(actualUrls, req.URL.String())
+			}
+			if !reflect.DeepEqual(actualUrls, param.ExpectedRequestUrls) {
+				t.Errorf("  Failed: actual -> %v, expected -> %v\n", actualUrls, param.ExpectedRequestUrls)
+			}
+		})
+	}
+}
+
+func TestIterateNextPage(t *testing.T) {
+	requests := make([]*http.Request, 0)
+	client := NewTestJsonboxClient(CreateNewSequenceTestClient([]TestResponse{
+		{StatusCode: 200, Body: `[{"_id":"id001"},{"_id":"id002"}]`},
+		{StatusCode: 200, Body: `[]`},
+	}, &requests))
+	it := client.Iterate("users", NewQueryBuilder(), 2)
+	pageCount := 0
+	for it.NextPage() {
+		pageCount++
+		if len(it.Page()) != 2 {
+			t.Errorf("  Failed: page -> %v\n", it.Page())
+		}
+	}
+	if it.Err() != nil || pageCount != 1 {
+		t.Errorf("  Failed: err -> %v, pageCount -> %v\n", it.Err(), pageCount)
+	}
+}
+
+func TestIterateCanceled(t *testing.T) {
+	requests := make([]*http.Request, 0)
+	client := NewTestJsonboxClient(CreateNewSequenceTestClient([]TestResponse{
+		{StatusCode: 200, Body: `[{"_id":"id001"}]`},
+	}, &requests))
+	ctx, cancel := context.WithCancel(context.Background())
+	it := client.IterateContext(ctx, "users", NewQueryBuilder(), 1)
+	if !it.Next() {
+		t.Fatalf("  Failed: err -> %v\n", it.Err())
+	}
+	cancel()
+	if it.Next() || !errors.Is(it.Err(), context.Canceled) || len(requests) != 1 {
+		t.Errorf("  Failed: err -> %v, requestCount -> %v\n", it.Err(), len(requests))
+	}
+}
+
+func TestIterateKeysetWithSort(t *testing.T) {
+	client := NewTestJsonboxClient(CreateNewTestClient(200, `[]`, 0, ``))
+	it := client.Iterate("users", NewQueryBuilder().SortAsc("age"), 10, KeysetPagination())
+	if it.Next() || it.Err() == nil {
+		t.Errorf("  Failed: err -> %v\n", it.Err())
+	}
+}
+
+func TestIterateKeysetStalled(t *testing.T) {
+	// more records than the server returns at once share the same _createdOn
+	records := make([]string, 0, ServerMaxLimit)
+	for i := 0; i < ServerMaxLimit; i++ {
+		records = append(records, fmt.Sprintf(`{"_id":"id%04d","_createdOn":"2020-01-01T00:00:00.000Z"}`, i))
+	}
+	body := "[" + strings.Join(records, ",") + "]"
+	requests := make([]*http.Request, 0)
+	client := NewTestJsonboxClient(CreateNewSequenceTestClient([]TestResponse{
+		{StatusCode: 200, Body: body},
+		{StatusCode: 200, Body: body},
+	}, &requests))
+	it := client.Iterate("users", NewQueryBuilder(), ServerMaxLimit, KeysetPagination())
+	count := 0
+	for it.Next() {
+		count++
+	}
+	if it.Err() == nil || count != ServerMaxLimit || len(requests) != 2 {
+		t.Errorf("  Failed: err -> %v, count -> %v, requestCount -> %v\n", it.Err(), count, len(requests))
+	}
+	expectedUrl := "https://test.com/box_test/users?offset=0&limit=1000&sort=_createdOn&q=_createdOn:>=2020-01-01T00:00:00.000Z"
+	if actualUrl := requests[1].URL.String(); actualUrl != expectedUrl {
+		t.Errorf("  Failed: actual -> %v, expected -> %v\n", actualUrl, expectedUrl)
+	}
+}
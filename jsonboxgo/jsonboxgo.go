@@ -1,252 +1,338 @@
 package jsonboxgo
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"log"
 	"net/http"
-	"strconv"
+	"net/url"
+	"regexp"
 	"strings"
+	"time"
 )
 
 type Client interface {
 	Create(string, interface{}) []byte
 	Read(string, string) ([]byte, bool)
 	ReadAll(string) []byte
-	ReadByQuery(string, QueryBuilder) []byte
+	ReadByQuery(string, Querier) []byte
 	Update(string, string, interface{}) ([]byte, bool)
 	Delete(string, string) ([]byte, bool)
+	CreateWithError(string, interface{}) ([]byte, error)
+	ReadWithError(string, string) ([]byte, error)
+	ReadAllWithError(string) ([]byte, error)
+	ReadByQueryWithError(string, Querier) ([]byte, error)
+	UpdateWithError(string, string, interface{}) ([]byte, error)
+	DeleteWithError(string, string) ([]byte, error)
+	CreateContext(context.Context, string, interface{}) ([]byte, error)
+	ReadContext(context.Context, string, string) ([]byte, error)
+	ReadAllContext(context.Context, string) ([]byte, error)
+	ReadByQueryContext(context.Context, string, Querier) ([]byte, error)
+	UpdateContext(context.Context, string, string, interface{}) ([]byte, error)
+	DeleteContext(context.Context, string, string) ([]byte, error)
+	CreateMany(string, []interface{}) ([][]byte, error)
+	CreateManyContext(context.Context, string, []interface{}) ([][]byte, error)
+	DeleteByQuery(string, Querier, ...BulkOption) (BulkResult, error)
+	DeleteByQueryContext(context.Context, string, Querier, ...BulkOption) (BulkResult, error)
+	UpdateByQuery(string, Querier, MutateFunc, ...BulkOption) (BulkResult, error)
+	UpdateByQueryContext(context.Context, string, Querier, MutateFunc, ...BulkOption) (BulkResult, error)
+	Iterate(string, Querier, int, ...IterateOption) *Iterator
+	IterateContext(context.Context, string, Querier, int, ...IterateOption) *Iterator
 }
 
 type DefaultClient struct {
 	baseUrl     string
 	boxId       string
+	basePath    string
 	baseUrlFull string
 	httpClient  *http.Client
+	timeout     time.Duration
+	header      http.Header
+	retryPolicy *RetryPolicy
+	rateLimiter *RateLimiter
+	batch       batchConfig
 }
 
+// boxIdPattern is the characters jsonbox accepts as a box id.
+var boxIdPattern = regexp.MustCompile(`^[0-9A-Za-z_]+$`)
+
 // Create new jsonbox-go Client
-func NewClient(baseUrl string, boxId string, httpClient *http.Client) Client {
+func NewClient(baseUrl string, boxId string, opts ...Option) (Client, error) {
+	parsedUrl, err := url.Parse(baseUrl)
+	if err != nil {
+		return nil, fmt.Errorf("jsonboxgo: invalid base url %q: %w", baseUrl, err)
+	}
+	if (parsedUrl.Scheme != "http" && parsedUrl.Scheme != "https") || parsedUrl.Host == "" || parsedUrl.RawQuery != "" || parsedUrl.Fragment != "" {
+		return nil, fmt.Errorf("jsonboxgo: invalid base url %q: must be an absolute http(s) url without query", baseUrl)
+	}
+	if !boxIdPattern.MatchString(strings.Trim(boxId, "/")) {
+		return nil, fmt.Errorf("jsonboxgo: invalid box id %q: must consist of alphanumeric characters and underscores", boxId)
+	}
 	client := DefaultClient{
-		baseUrl:     baseUrl,
-		boxId:       boxId,
-		baseUrlFull: handleSuffix(baseUrl) + handleSuffixAndPrefix(boxId),
-		httpClient:  httpClient,
+		baseUrl:    baseUrl,
+		boxId:      boxId,
+		httpClient: http.DefaultClient,
+		header:     make(http.Header),
+		batch:      defaultBatchConfig(),
 	}
-	return client
+	for _, opt := range opts {
+		if err := opt(&client); err != nil {
+			return nil, err
+		}
+	}
+	client.baseUrlFull = parsedUrl.JoinPath(strings.Trim(client.basePath, "/"), strings.Trim(boxId, "/")).String()
+	return client, nil
 }
 
 // Create
 func (c DefaultClient) Create(collection string, object interface{}) []byte {
-	resp, err := c.doRequest("POST", collection, "", "", object)
-	if err != nil {
-		log.Fatal("Create failed. | ", err)
-	}
-	return readAsBytes(resp)
+	body, err := c.CreateWithError(collection, object)
+	return bodyOrFatal("Create", body, err)
 }
 
 // Read all
 func (c DefaultClient) ReadAll(collection string) []byte {
-	resp, err := c.doRequest("GET", collection, "", "", nil)
-	if err != nil {
-		log.Fatal("ReadAll failed. | ", err)
-	}
-	return readAsBytes(resp)
+	body, err := c.ReadAllWithError(collection)
+	return bodyOrFatal("ReadAll", body, err)
 }
 
-// Read all
-func (c DefaultClient) ReadByQuery(collection string, query QueryBuilder) []byte {
-	resp, err := c.doRequest("GET", collection, "", query.Build(), nil)
-	if err != nil {
-		log.Fatal("ReadByQuery failed. | ", err)
-	}
-	return readAsBytes(resp)
+// Read by query
+func (c DefaultClient) ReadByQuery(collection string, query Querier) []byte {
+	body, err := c.ReadByQueryWithError(collection, query)
+	return bodyOrFatal("ReadByQuery", body, err)
 }
 
 // Read one
 func (c DefaultClient) Read(collection string, recordId string) (respondedBody []byte, found bool) {
-	resp, err := c.doRequest("GET", collection, recordId, "", nil)
-	if err != nil {
-		log.Fatal("Read failed. | ", err)
-	}
-	if resp.StatusCode != http.StatusOK {
-		return nil, false
-	}
-	bytes := readAsBytes(resp)
-	// list type json object is unexpected.
-	var listObject []interface{}
-	if json.Unmarshal(bytes, &listObject) == nil {
-		return nil, false
-	}
-	return bytes, true
+	body, err := c.ReadWithError(collection, recordId)
+	return body, succeededOrFatal("Read", err)
 }
 
 // Update
 func (c DefaultClient) Update(collection string, recordId string, object interface{}) (respondedBody []byte, updated bool) {
-	resp, err := c.doRequest("PUT", collection, recordId, "", object)
-	if err != nil {
-		log.Fatal("Update failed. | ", err)
-	}
-	if resp.StatusCode != http.StatusOK {
-		return nil, false
-	}
-	return c.Read(collection, recordId)
+	body, err := c.UpdateWithError(collection, recordId, object)
+	return body, succeededOrFatal("Update", err)
 }
 
 // Delete
 func (c DefaultClient) Delete(collection string, recordId string) (respondedBody []byte, deleted bool) {
-	resp, err := c.doRequest("DELETE", collection, recordId, "", nil)
-	if err != nil {
-		log.Fatal("Delete failed. | ", err)
-	}
-	if resp.StatusCode != http.StatusOK {
-		return nil, false
-	}
-	return readAsBytes(resp), true
+	body, err := c.DeleteWithError(collection, recordId)
+	return body, succeededOrFatal("Delete", err)
 }
 
-func (c DefaultClient) doRequest(httpMethod string, collection string, recordId string, query string, object interface{}) (*http.Response, error) {
-	var body io.Reader = nil
-	if object != nil {
-		requestBody := toJsonString(object)
-		body = strings.NewReader(requestBody)
-	}
-	req, err := http.NewRequest(httpMethod, c.baseUrlFull+handleSuffixAndPrefix(collection)+handleSuffixAndPrefix(recordId)+query, body)
-	if err != nil {
-		log.Fatal(`http.NewRequest("`+httpMethod+`") failed. | `, err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-	return c.httpClient.Do(req)
+// Create, returns an error instead of exiting the process
+func (c DefaultClient) CreateWithError(collection string, object interface{}) ([]byte, error) {
+	return c.CreateContext(context.Background(), collection, object)
 }
 
-func readAsBytes(resp *http.Response) []byte {
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		log.Fatal("ioutil.ReadAll() failed. | ", err)
-	}
-	defer func() {
-		err := resp.Body.Close()
-		if err != nil {
-			log.Panic("resp.Body.Close() failed. | ", err)
-		}
-	}()
-	return body
+// Read all, returns an error instead of exiting the process
+func (c DefaultClient) ReadAllWithError(collection string) ([]byte, error) {
+	return c.ReadAllContext(context.Background(), collection)
 }
 
-// Convert to json string
-func toJsonString(v interface{}) string {
-	result, _ := json.Marshal(v)
-	return string(result)
+// Read by query, returns an error instead of exiting the process
+func (c DefaultClient) ReadByQueryWithError(collection string, query Querier) ([]byte, error) {
+	return c.ReadByQueryContext(context.Background(), collection, query)
 }
 
-// Adjust suffix
-func handleSuffix(char string) string {
-	if strings.HasSuffix(char, "/") {
-		return strings.TrimRight(char, "/")
-	}
-	return char
+// Read one, returns an error instead of exiting the process
+func (c DefaultClient) ReadWithError(collection string, recordId string) ([]byte, error) {
+	return c.ReadContext(context.Background(), collection, recordId)
 }
 
-// Adjust prefix
-func handlePrefix(char string) string {
-	if !strings.HasPrefix(char, "/") {
-		return "/" + char
-	}
-	return char
+// Update, returns an error instead of exiting the process
+func (c DefaultClient) UpdateWithError(collection string, recordId string, object interface{}) ([]byte, error) {
+	return c.UpdateContext(context.Background(), collection, recordId, object)
 }
 
-// Adjust suffix and prefix
-func handleSuffixAndPrefix(char string) string {
-	return handlePrefix(handleSuffix(char))
+// Delete, returns an error instead of exiting the process
+func (c DefaultClient) DeleteWithError(collection string, recordId string) ([]byte, error) {
+	return c.DeleteContext(context.Background(), collection, recordId)
 }
 
-type QueryBuilder interface {
-	Offset(int) QueryBuilder
-	Limit(int) QueryBuilder
-	SortAsc(string) QueryBuilder
-	SortDesc(string) QueryBuilder
-	AndEqual(string, string) QueryBuilder
-	AndGreaterThan(string, string) QueryBuilder
-	AndGreaterThanOrEqual(string, string) QueryBuilder
-	AndLessThan(string, string) QueryBuilder
-	AndLessThanOrEqual(string, string) QueryBuilder
-	Build() string
+// Create with context
+func (c DefaultClient) CreateContext(ctx context.Context, collection string, object interface{}) ([]byte, error) {
+	return c.do(ctx, "POST", collection, "", "", object)
 }
 
-type DefaultQueryBuilder struct {
-	queries []string
-	filters []string
+// Read all with context
+func (c DefaultClient) ReadAllContext(ctx context.Context, collection string) ([]byte, error) {
+	return c.do(ctx, "GET", collection, "", "", nil)
 }
 
-// Create new jsonbox-go QueryBuilder
-func NewQueryBuilder() QueryBuilder {
-	builder := &DefaultQueryBuilder{
-		queries: make([]string, 0),
-		filters: make([]string, 0),
+// Read by query with context
+func (c DefaultClient) ReadByQueryContext(ctx context.Context, collection string, query Querier) ([]byte, error) {
+	if err := query.Err(); err != nil {
+		return nil, err
 	}
-	return builder
+	return c.do(ctx, "GET", collection, "", query.Build(), nil)
 }
 
-func (d *DefaultQueryBuilder) Offset(offset int) QueryBuilder {
-	d.queries = append(d.queries, `offset=`+strconv.Itoa(offset))
-	return d
-}
-
-func (d *DefaultQueryBuilder) Limit(limit int) QueryBuilder {
-	d.queries = append(d.queries, `limit=`+strconv.Itoa(limit))
-	return d
+// Read one with context
+func (c DefaultClient) ReadContext(ctx context.Context, collection string, recordId string) ([]byte, error) {
+	body, err := c.do(ctx, "GET", collection, recordId, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	// list type json object is unexpected.
+	var listObject []interface{}
+	if json.Unmarshal(body, &listObject) == nil {
+		return nil, fmt.Errorf("%w: responded a list for record id %q", ErrNotFound, recordId)
+	}
+	return body, nil
 }
 
-func (d *DefaultQueryBuilder) SortAsc(sort string) QueryBuilder {
-	d.queries = append(d.queries, `sort=`+sort)
-	return d
+// Update with context, the updated record is read back after the update succeeded
+func (c DefaultClient) UpdateContext(ctx context.Context, collection string, recordId string, object interface{}) ([]byte, error) {
+	if _, err := c.do(ctx, "PUT", collection, recordId, "", object); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("jsonboxgo: read back after PUT canceled: %w", err)
+	}
+	return c.ReadContext(ctx, collection, recordId)
 }
 
-func (d *DefaultQueryBuilder) SortDesc(sort string) QueryBuilder {
-	d.queries = append(d.queries, `sort=-`+sort)
-	return d
+// Delete with context
+func (c DefaultClient) DeleteContext(ctx context.Context, collection string, recordId string) ([]byte, error) {
+	return c.do(ctx, "DELETE", collection, recordId, "", nil)
 }
 
-func (d *DefaultQueryBuilder) AndGreaterThan(field string, value string) QueryBuilder {
-	return d.addFilter(field, ":>", value)
+// Send request and read the responded body, non-2xx status code is returned as an error
+func (c DefaultClient) do(ctx context.Context, httpMethod string, collection string, recordId string, query string, object interface{}) ([]byte, error) {
+	requestUrl := c.requestUrl(collection, recordId, query)
+	var requestBody []byte
+	if object != nil {
+		var err error
+		requestBody, err = json.Marshal(object)
+		if err != nil {
+			return nil, fmt.Errorf("jsonboxgo: json.Marshal() failed: %w", err)
+		}
+	}
+	return c.doWithRetry(ctx, httpMethod, requestUrl, requestBody)
 }
 
-func (d *DefaultQueryBuilder) AndLessThan(field string, value string) QueryBuilder {
-	return d.addFilter(field, ":<", value)
+// Build the url of the collection or the record, each path segment is escaped
+func (c DefaultClient) requestUrl(collection string, recordId string, query string) string {
+	requestUrl := c.baseUrlFull
+	for _, segment := range []string{collection, recordId} {
+		if segment = strings.Trim(segment, "/"); segment != "" {
+			requestUrl += "/" + url.PathEscape(segment)
+		}
+	}
+	if query == "?" {
+		return requestUrl
+	}
+	return requestUrl + query
 }
 
-func (d *DefaultQueryBuilder) AndGreaterThanOrEqual(field string, value string) QueryBuilder {
-	return d.addFilter(field, ":>=", value)
+// Send request once, the timeout is applied to each attempt
+func (c DefaultClient) doOnce(ctx context.Context, httpMethod string, requestUrl string, requestBody []byte, header http.Header) ([]byte, error) {
+	if c.rateLimiter != nil {
+		if err := c.rateLimiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	resp, err := c.doRequest(ctx, httpMethod, requestUrl, requestBody, header)
+	if err != nil {
+		return nil, err
+	}
+	if c.rateLimiter != nil {
+		c.rateLimiter.observe(resp.StatusCode, resp.Header)
+	}
+	body, err := readAsBytes(resp)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := newAPIError(httpMethod, requestUrl, resp.StatusCode, body)
+		apiErr.Header = resp.Header
+		return nil, apiErr
+	}
+	return body, nil
 }
 
-func (d *DefaultQueryBuilder) AndLessThanOrEqual(field string, value string) QueryBuilder {
-	return d.addFilter(field, ":<=", value)
+func (c DefaultClient) doRequest(ctx context.Context, httpMethod string, requestUrl string, requestBody []byte, header http.Header) (*http.Response, error) {
+	var body io.Reader = nil
+	if requestBody != nil {
+		body = bytes.NewReader(requestBody)
+	}
+	req, err := http.NewRequestWithContext(ctx, httpMethod, requestUrl, body)
+	if err != nil {
+		return nil, fmt.Errorf("jsonboxgo: http.NewRequestWithContext(%q) failed: %w", httpMethod, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, values := range c.header {
+		req.Header[key] = values
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		// make sure cancellation and deadline expiry can be detected by errors.Is
+		if ctxErr := ctx.Err(); ctxErr != nil && !errors.Is(err, ctxErr) {
+			return nil, fmt.Errorf("jsonboxgo: %s request failed: %w (%v)", httpMethod, ctxErr, err)
+		}
+		return nil, fmt.Errorf("jsonboxgo: %s request failed: %w", httpMethod, err)
+	}
+	return resp, nil
 }
 
-func (d *DefaultQueryBuilder) AndEqual(field string, value string) QueryBuilder {
-	return d.addFilter(field, ":=", value)
+func readAsBytes(resp *http.Response) (body []byte, err error) {
+	defer func() {
+		closeErr := resp.Body.Close()
+		if err == nil && closeErr != nil {
+			err = fmt.Errorf("jsonboxgo: resp.Body.Close() failed: %w", closeErr)
+		}
+	}()
+	body, err = ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("jsonboxgo: ioutil.ReadAll() failed: %w", err)
+	}
+	return body, nil
 }
 
-func (d *DefaultQueryBuilder) addFilter(name string, operator string, value string) QueryBuilder {
-	filterQuery := ""
-	if len(d.filters) == 0 {
-		filterQuery += "q="
+// Return the responded body even if the status code is not 2xx, exit on transport failure.
+// An invalid query or a request rejected by the fail fast rate limiter is not sent, so there is no body.
+func bodyOrFatal(operation string, body []byte, err error) []byte {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Body
+	}
+	if isNotSent(err) {
+		return nil
 	}
-	d.filters = append(d.filters, filterQuery+name+operator+value)
-	return d
+	if err != nil {
+		log.Fatal(operation+" failed. | ", err)
+	}
+	return body
 }
 
-func (d *DefaultQueryBuilder) Build() string {
-	query := ""
-	if len(d.queries) > 0 {
-		query += strings.Join(d.queries, `&`)
+// Report whether the operation succeeded, exit on transport failure
+func succeededOrFatal(operation string, err error) bool {
+	var apiErr *APIError
+	if err == nil {
+		return true
 	}
-	if len(d.filters) > 0 {
-		if query != "" {
-			query += "&"
-		}
-		query += strings.Join(d.filters, `,`)
+	if errors.As(err, &apiErr) || errors.Is(err, ErrNotFound) || isNotSent(err) {
+		return false
 	}
-	return "?" + query
+	log.Fatal(operation+" failed. | ", err)
+	return false
+}
+
+// Report whether the request was refused before it was sent
+func isNotSent(err error) bool {
+	return errors.Is(err, ErrInvalidQuery) || errors.Is(err, ErrRateLimitExceeded)
 }
@@ -2,9 +2,12 @@ package jsonboxgo
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"io/ioutil"
 	"net/http"
 	"testing"
+	"time"
 )
 
 type RoundTripFunc func(req *http.Request) *http.Response
@@ -45,6 +48,14 @@ func CreateNewTestClient(statusCode int, respondedBody string, statusCodeSecond
 	}())
 }
 
+func NewTestJsonboxClient(httpClient *http.Client) Client {
+	client, err := NewClient("https://test.com", "box_test", WithHTTPClient(httpClient))
+	if err != nil {
+		panic(err)
+	}
+	return client
+}
+
 type User struct {
 	Id   string `json:"_id,omitempty"`
 	Name string `json:"name,omitempty"`
@@ -78,7 +89,7 @@ func TestNewClient(t *testing.T) {
 	// run
 	for testCase, param := range testCases {
 		t.Run(testCase, func(t *testing.T) {
-			client := NewClient(param.InputBaseUrl, param.InputBoxId, nil)
+			client, _ := NewClient(param.InputBaseUrl, param.InputBoxId)
 			t.Logf("Case:%v\n", param.TestCase)
 			defaultClient := client.(DefaultClient)
 			actual := defaultClient.baseUrlFull
@@ -118,7 +129,7 @@ func TestCreate(t *testing.T) {
 	for testCase, param := range testCases {
 		t.Run(testCase, func(t *testing.T) {
 			mockHttpClient := CreateNewTestClient(200, param.InputRespondedBody, 0, ``)
-			client := NewClient(InputBaseUrl, InputBoxId, mockHttpClient)
+			client, _ := NewClient(InputBaseUrl, InputBoxId, WithHTTPClient(mockHttpClient))
 			defaultClient, _ := client.(DefaultClient)
 			result := defaultClient.Create(param.InputCollection, param.InputObject)
 			actual := string(result)
@@ -186,7 +197,7 @@ func TestRead(t *testing.T) {
 				0,
 				``,
 			)
-			client := NewClient(InputBaseUrl, InputBoxId, mockHttpClient)
+			client, _ := NewClient(InputBaseUrl, InputBoxId, WithHTTPClient(mockHttpClient))
 			defaultClient, _ := client.(DefaultClient)
 			result, found := defaultClient.Read(param.InputCollection, param.InputObject.Id)
 			actual := string(result)
@@ -238,7 +249,7 @@ func TestReadAll(t *testing.T) {
 	for testCase, param := range testCases {
 		t.Run(testCase, func(t *testing.T) {
 			mockHttpClient := CreateNewTestClient(param.InputRespondedHttpStatus, param.InputRespondedBody, 0, ``)
-			client := NewClient(InputBaseUrl, InputBoxId, mockHttpClient)
+			client, _ := NewClient(InputBaseUrl, InputBoxId, WithHTTPClient(mockHttpClient))
 			defaultClient, _ := client.(DefaultClient)
 			result := defaultClient.ReadAll(param.InputCollection)
 			actual := string(result)
@@ -303,7 +314,7 @@ func TestUpdate(t *testing.T) {
 				param.InputRespondedHttpStatusSecond,
 				param.InputRespondedBodySecond,
 			)
-			client := NewClient(InputBaseUrl, InputBoxId, mockHttpClient)
+			client, _ := NewClient(InputBaseUrl, InputBoxId, WithHTTPClient(mockHttpClient))
 			defaultClient, _ := client.(DefaultClient)
 			result, updated := defaultClient.Update(param.InputCollection, param.InputObject.Id, param.InputObject)
 			actual := string(result)
@@ -368,7 +379,7 @@ func TestDelete(t *testing.T) {
 				0,
 				``,
 			)
-			client := NewClient(InputBaseUrl, InputBoxId, mockHttpClient)
+			client, _ := NewClient(InputBaseUrl, InputBoxId, WithHTTPClient(mockHttpClient))
 			defaultClient, _ := client.(DefaultClient)
 			result, deleted := defaultClient.Delete(param.InputCollection, param.InputObject.Id)
 			actual := string(result)
@@ -388,3 +399,180 @@ func TestDelete(t *testing.T) {
 		})
 	}
 }
+
+type RoundTripErrorFunc func(req *http.Request) (*http.Response, error)
+
+func (f RoundTripErrorFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestWithError(t *testing.T) {
+	// test cases
+	InputBaseUrl := "https://test.com"
+	InputBoxId := "box_test"
+	testCases := map[string]struct {
+		InputHttpClient       *http.Client
+		InputOperation        func(Client) ([]byte, error)
+		ExpectedRespondedBody string
+		ExpectedError         bool
+		ExpectedStatusCode    int
+	}{
+		"Create succeeded.": {
+			InputHttpClient: CreateNewTestClient(200, `{"_id":"id001","name":"taro"}`, 0, ``),
+			InputOperation: func(c Client) ([]byte, error) {
+				return c.CreateWithError("users", User{Name: "taro"})
+			},
+			ExpectedRespondedBody: `{"_id":"id001","name":"taro"}`,
+		},
+		"Create failed by status code.": {
+			InputHttpClient: CreateNewTestClient(500, `{"message":"Internal Server Error"}`, 0, ``),
+			InputOperation: func(c Client) ([]byte, error) {
+				return c.CreateWithError("users", User{Name: "taro"})
+			},
+			ExpectedError:      true,
+			ExpectedStatusCode: 500,
+		},
+		"Create failed by marshalling.": {
+			InputHttpClient: CreateNewTestClient(200, `{}`, 0, ``),
+			InputOperation: func(c Client) ([]byte, error) {
+				return c.CreateWithError("users", func() {})
+			},
+			ExpectedError: true,
+		},
+		"ReadAll failed by transport.": {
+			InputHttpClient: &http.Client{Transport: RoundTripErrorFunc(func(req *http.Request) (*http.Response, error) {
+				return nil, errors.New("connection reset by peer")
+			})},
+			InputOperation: func(c Client) ([]byte, error) {
+				return c.ReadAllWithError("users")
+			},
+			ExpectedError: true,
+		},
+		"ReadByQuery succeeded.": {
+			InputHttpClient: CreateNewTestClient(200, `[{"_id":"id001","name":"taro"}]`, 0, ``),
+			InputOperation: func(c Client) ([]byte, error) {
+				return c.ReadByQueryWithError("users", NewQueryBuilder().Limit(1))
+			},
+			ExpectedRespondedBody: `[{"_id":"id001","name":"taro"}]`,
+		},
+		"Read responded list.": {
+			InputHttpClient: CreateNewTestClient(200, `[{"_id":"id002","name":"taro"}]`, 0, ``),
+			InputOperation: func(c Client) ([]byte, error) {
+				return c.ReadWithError("users", "id001")
+			},
+			ExpectedError: true,
+		},
+		"Update failed by status code.": {
+			InputHttpClient: CreateNewTestClient(400, `{"message":"Invalid record Id"}`, 0, ``),
+			InputOperation: func(c Client) ([]byte, error) {
+				return c.UpdateWithError("users", "id001", User{Name: "taro"})
+			},
+			ExpectedError:      true,
+			ExpectedStatusCode: 400,
+		},
+		"Delete succeeded.": {
+			InputHttpClient: CreateNewTestClient(200, `{"message":"Record removed."}`, 0, ``),
+			InputOperation: func(c Client) ([]byte, error) {
+				return c.DeleteWithError("users", "id001")
+			},
+			ExpectedRespondedBody: `{"message":"Record removed."}`,
+		},
+	}
+
+	// run
+	for testCase, param := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			client, _ := NewClient(InputBaseUrl, InputBoxId, WithHTTPClient(param.InputHttpClient))
+			result, err := param.InputOperation(client)
+			actual := string(result)
+			expected := param.ExpectedRespondedBody
+			if actual != expected {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+			}
+			if (err != nil) != param.ExpectedError {
+				t.Errorf("  Failed: err -> %v(%T), expectedError -> %v\n", err, err, param.ExpectedError)
+			}
+			var apiErr *APIError
+			if param.ExpectedStatusCode != 0 && (!errors.As(err, &apiErr) || apiErr.StatusCode != param.ExpectedStatusCode) {
+				t.Errorf("  Failed: err -> %v(%T), expectedStatusCode -> %v\n", err, err, param.ExpectedStatusCode)
+			}
+		})
+	}
+}
+
+func TestContext(t *testing.T) {
+	// test cases
+	InputBaseUrl := "https://test.com"
+	InputBoxId := "box_test"
+	testCases := map[string]struct {
+		InputOperation       func(context.Context, Client) ([]byte, error)
+		InputCancelOnRequest bool
+		InputTimeout         time.Duration
+		ExpectedError        error
+		ExpectedRequestCount int
+	}{
+		"ReadAll canceled before request.": {
+			InputOperation: func(ctx context.Context, c Client) ([]byte, error) {
+				return c.ReadAllContext(ctx, "users")
+			},
+			ExpectedError:        context.Canceled,
+			ExpectedRequestCount: 0,
+		},
+		"ReadByQuery deadline exceeded.": {
+			InputOperation: func(ctx context.Context, c Client) ([]byte, error) {
+				return c.ReadByQueryContext(ctx, "users", NewQueryBuilder().Limit(1))
+			},
+			InputTimeout:         time.Nanosecond,
+			ExpectedError:        context.DeadlineExceeded,
+			ExpectedRequestCount: 0,
+		},
+		"Update canceled before read back.": {
+			InputOperation: func(ctx context.Context, c Client) ([]byte, error) {
+				return c.UpdateContext(ctx, "users", "id001", User{Name: "taro"})
+			},
+			InputCancelOnRequest: true,
+			ExpectedError:        context.Canceled,
+			ExpectedRequestCount: 1,
+		},
+	}
+
+	// run
+	for testCase, param := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if param.InputTimeout > 0 {
+				ctx, cancel = context.WithTimeout(context.Background(), param.InputTimeout)
+				defer cancel()
+				time.Sleep(param.InputTimeout)
+			} else if !param.InputCancelOnRequest {
+				cancel()
+			}
+			requestCount := 0
+			mockHttpClient := &http.Client{Transport: RoundTripErrorFunc(func(req *http.Request) (*http.Response, error) {
+				if err := req.Context().Err(); err != nil {
+					return nil, err
+				}
+				requestCount++
+				if param.InputCancelOnRequest {
+					cancel()
+				}
+				return &http.Response{
+					StatusCode: 200,
+					Body:       ioutil.NopCloser(bytes.NewBufferString(`{"message":"Record updated."}`)),
+					Header:     make(http.Header),
+				}, nil
+			})}
+			client, _ := NewClient(InputBaseUrl, InputBoxId, WithHTTPClient(mockHttpClient))
+			_, err := param.InputOperation(ctx, client)
+			if !errors.Is(err, param.ExpectedError) {
+				t.Errorf("  Failed: err -> %v(%T), expected -> %v\n", err, err, param.ExpectedError)
+			}
+			actual := requestCount
+			expected := param.ExpectedRequestCount
+			if actual != expected {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,343 @@
+package jsonboxgo
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ServerDefaultLimit is the number of records jsonbox responds when the limit is not given.
+	ServerDefaultLimit = 20
+	// ServerMaxLimit is the maximum number of records jsonbox responds at once.
+	ServerMaxLimit = 1000
+)
+
+// numberPattern is a number in the form jsonbox converts a filter value to a number
+var numberPattern = regexp.MustCompile(`^-?(\d+\.?\d*|\.\d+)([eE][-+]?\d+)?$`)
+
+// Matcher evaluates the filters, sort, offset and limit of a query against decoded JSON records
+// with jsonbox's comparison semantics.
+//
+//   - ":=" and the range operators compare a number with numbers when the value is a number, otherwise a string with strings.
+//     ":=true" and ":=false" compare booleans. "40" in a record does not equal "age:=40", use "age:40" instead.
+//   - ":" matches strings by a case-insensitive regular expression, a leading or trailing "*" removes the anchor of that end.
+//   - Dotted fields such as "user.age" look into nested objects (see Path), and an array matches when any of its elements matches.
+//   - Records are sorted like MongoDB: missing < numbers < strings < objects < arrays < booleans.
+//     Without a sort the records keep their order.
+//   - The limit is ServerDefaultLimit when not given and at most ServerMaxLimit.
+type Matcher struct {
+	conditions []condition
+	sort       string
+	descending bool
+	offset     int
+	limit      int
+}
+
+// A filter with its value converted for comparison
+type condition struct {
+	path     []string
+	operator Operator
+	value    interface{}
+	pattern  *regexp.Regexp
+}
+
+// Create new Matcher of the query
+func NewMatcher(query Querier) (*Matcher, error) {
+	if err := query.Err(); err != nil {
+		return nil, err
+	}
+	builder, err := parseQueryString(query.Build())
+	if err != nil {
+		return nil, err
+	}
+	m := &Matcher{limit: ServerDefaultLimit}
+	if offset := builder.param("offset"); offset != "" {
+		m.offset, _ = strconv.Atoi(offset)
+	}
+	if limit := builder.param("limit"); limit != "" {
+		m.limit, _ = strconv.Atoi(limit)
+		if m.limit > ServerMaxLimit {
+			m.limit = ServerMaxLimit
+		}
+	}
+	if sort := builder.param("sort"); sort != "" {
+		m.sort = strings.TrimPrefix(sort, "-")
+		m.descending = strings.HasPrefix(sort, "-")
+	}
+	for _, f := range builder.filters {
+		m.conditions = append(m.conditions, newCondition(f))
+	}
+	return m, nil
+}
+
+// Create the condition of the filter
+func newCondition(f filter) condition {
+	c := condition{path: splitPath(f.field), operator: Operator(f.operator)}
+	switch c.operator {
+	case OpMatch:
+		// the pattern was validated when the filter was added
+		c.pattern, _ = compilePattern(f.value)
+	case OpEqual:
+		c.value = parseComparable(f.value, true)
+	default:
+		c.value = parseComparable(f.value, false)
+	}
+	return c
+}
+
+// Match reports whether the record satisfies all filters of the query.
+func (m *Matcher) Match(record map[string]interface{}) bool {
+	for _, c := range m.conditions {
+		if !c.match(record) {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply returns the records matching the filters, sorted and sliced by the offset and limit.
+// The given slice is not modified.
+func (m *Matcher) Apply(records []map[string]interface{}) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0)
+	for _, i := range m.selectRecords(records) {
+		result = append(result, records[i])
+	}
+	return result
+}
+
+// ApplyJSON is Apply for JSON encoded records.
+func (m *Matcher) ApplyJSON(records [][]byte) ([][]byte, error) {
+	decoded := make([]map[string]interface{}, 0, len(records))
+	for _, record := range records {
+		object, err := decodeRecord(record)
+		if err != nil {
+			return nil, err
+		}
+		decoded = append(decoded, object)
+	}
+	result := make([][]byte, 0)
+	for _, i := range m.selectRecords(decoded) {
+		result = append(result, records[i])
+	}
+	return result, nil
+}
+
+// Indexes of the records to respond in order
+func (m *Matcher) selectRecords(records []map[string]interface{}) []int {
+	selected := make([]int, 0)
+	for i, record := range records {
+		if m.Match(record) {
+			selected = append(selected, i)
+		}
+	}
+	if m.sort != "" {
+		path := splitPath(m.sort)
+		sort.SliceStable(selected, func(i, j int) bool {
+			result := compareValues(sortValue(records[selected[i]], path), sortValue(records[selected[j]], path))
+			if m.descending {
+				return result > 0
+			}
+			return result < 0
+		})
+	}
+	if m.offset >= len(selected) {
+		return selected[:0]
+	}
+	selected = selected[m.offset:]
+	if m.limit < len(selected) {
+		selected = selected[:m.limit]
+	}
+	return selected
+}
+
+// Decode a JSON object keeping numbers as json.Number
+func decodeRecord(record []byte) (map[string]interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(record))
+	decoder.UseNumber()
+	object := make(map[string]interface{})
+	if err := decoder.Decode(&object); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+// Convert the filter value the way jsonbox does, numbers and (for ":=") booleans are typed
+func parseComparable(value string, equal bool) interface{} {
+	if numberPattern.MatchString(value) {
+		if number, err := strconv.ParseFloat(value, 64); err == nil && !math.IsInf(number, 0) {
+			return number
+		}
+	}
+	if equal && (value == "true" || value == "false") {
+		return value == "true"
+	}
+	return value
+}
+
+func (c condition) match(record map[string]interface{}) bool {
+	for _, value := range lookupPath(record, c.path) {
+		if c.matchValue(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c condition) matchValue(value interface{}) bool {
+	if values, ok := value.([]interface{}); ok {
+		for _, element := range values {
+			if c.matchValue(element) {
+				return true
+			}
+		}
+		return false
+	}
+	if c.operator == OpMatch {
+		s, ok := value.(string)
+		return ok && c.pattern.MatchString(s)
+	}
+	value = normalizeValue(value)
+	if typeOrder(value) != typeOrder(c.value) {
+		return false
+	}
+	result := compareValues(value, c.value)
+	switch c.operator {
+	case OpEqual:
+		return result == 0
+	case OpGreaterThan:
+		return result > 0
+	case OpGreaterThanOrEqual:
+		return result >= 0
+	case OpLessThan:
+		return result < 0
+	case OpLessThanOrEqual:
+		return result <= 0
+	}
+	return false
+}
+
+// Values at the dotted path, arrays of objects are looked into element by element
+func lookupPath(value interface{}, path []string) []interface{} {
+	if len(path) == 0 {
+		return []interface{}{value}
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		child, ok := v[path[0]]
+		if !ok {
+			return nil
+		}
+		return lookupPath(child, path[1:])
+	case []interface{}:
+		values := make([]interface{}, 0)
+		for _, element := range v {
+			if _, ok := element.(map[string]interface{}); ok {
+				values = append(values, lookupPath(element, path)...)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// The value a record is sorted by, nil when missing
+func sortValue(record map[string]interface{}, path []string) interface{} {
+	values := lookupPath(record, path)
+	if len(values) == 0 {
+		return nil
+	}
+	return normalizeValue(values[0])
+}
+
+// Convert numbers to float64 so that they can be compared
+func normalizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if number, err := v.Float64(); err == nil {
+			return number
+		}
+		return v.String()
+	case float32:
+		return float64(v)
+	case int:
+		return float64(v)
+	case int8:
+		return float64(v)
+	case int16:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint:
+		return float64(v)
+	case uint8:
+		return float64(v)
+	case uint16:
+		return float64(v)
+	case uint32:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	}
+	return value
+}
+
+// Order of the types in MongoDB's sort order
+func typeOrder(value interface{}) int {
+	switch value.(type) {
+	case nil:
+		return 0
+	case float64:
+		return 1
+	case string:
+		return 2
+	case map[string]interface{}:
+		return 3
+	case []interface{}:
+		return 4
+	case bool:
+		return 5
+	}
+	return 6
+}
+
+// Compare normalized values, -1, 0 or 1
+func compareValues(a interface{}, b interface{}) int {
+	if orderA, orderB := typeOrder(a), typeOrder(b); orderA != orderB {
+		if orderA < orderB {
+			return -1
+		}
+		return 1
+	}
+	switch va := a.(type) {
+	case nil:
+		return 0
+	case float64:
+		vb := b.(float64)
+		if va < vb {
+			return -1
+		} else if va > vb {
+			return 1
+		}
+		return 0
+	case string:
+		return strings.Compare(va, b.(string))
+	case bool:
+		vb := b.(bool)
+		if va == vb {
+			return 0
+		} else if !va {
+			return -1
+		}
+		return 1
+	}
+	encodedA, _ := json.Marshal(a)
+	encodedB, _ := json.Marshal(b)
+	return bytes.Compare(encodedA, encodedB)
+}
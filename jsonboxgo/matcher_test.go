@@ -0,0 +1,215 @@
+package jsonboxgo
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var matcherRecords = [][]byte{
+	[]byte(`{"_id":"1","name":"Taro Yamada","age":40,"country":"JP","active":true,"tags":["a","b"],"user":{"rank":3},"_createdOn":"2020-04-27T14:14:29.843Z"}`),
+	[]byte(`{"_id":"2","name":"Jiro","age":20,"country":"US","active":false,"tags":["c"],"user":{"rank":1},"_createdOn":"2020-04-28T14:14:29.843Z"}`),
+	[]byte(`{"_id":"3","name":"hanako*","age":"40","country":"JP","_createdOn":"2020-04-29T14:14:29.843Z"}`),
+	[]byte(`{"_id":"4","name":"Saburo","age":35.5,"country":"JP","items":[{"price":100},{"price":300}],"_createdOn":"2020-04-30T14:14:29.843Z"}`),
+}
+
+func TestMatcher(t *testing.T) {
+	// test cases
+	testCases := map[string]struct {
+		InputQuery    Querier
+		ExpectedIds   []string
+		ExpectedError error
+	}{
+		"No filter.": {
+			InputQuery:  NewQueryBuilder(),
+			ExpectedIds: []string{"1", "2", "3", "4"},
+		},
+		"Equal number does not match string.": {
+			InputQuery:  NewQueryBuilder().AndEqualInt("age", 40),
+			ExpectedIds: []string{"1"},
+		},
+		"Match string.": {
+			InputQuery:  NewQueryBuilder().AndMatch("age", "40"),
+			ExpectedIds: []string{"3"},
+		},
+		"Equal string.": {
+			InputQuery:  NewQueryBuilder().AndEqual("country", "JP"),
+			ExpectedIds: []string{"1", "3", "4"},
+		},
+		"Equal bool.": {
+			InputQuery:  NewQueryBuilder().AndEqualBool("active", false),
+			ExpectedIds: []string{"2"},
+		},
+		"Range compares numbers only.": {
+			InputQuery:  NewQueryBuilder().AndGreaterThanOrEqualFloat("age", 35.5),
+			ExpectedIds: []string{"1", "4"},
+		},
+		"Range of strings.": {
+			InputQuery:  NewQueryBuilder().AndLessThan("name", "S"),
+			ExpectedIds: []string{"2"},
+		},
+		"Range of time.": {
+			InputQuery:  NewQueryBuilder().AndCreatedAfter(time.Date(2020, 4, 28, 14, 14, 29, 843000000, time.UTC)),
+			ExpectedIds: []string{"3", "4"},
+		},
+		"Wildcard is case-insensitive.": {
+			InputQuery:  NewQueryBuilder().AndStartsWith("name", "taro"),
+			ExpectedIds: []string{"1"},
+		},
+		"Whole match.": {
+			InputQuery:  NewQueryBuilder().AndMatch("name", "jiro"),
+			ExpectedIds: []string{"2"},
+		},
+		"Escaped asterisk.": {
+			InputQuery:  NewQueryBuilder().AndEndsWith("name", "o*"),
+			ExpectedIds: []string{"3"},
+		},
+		"Match is a regular expression.": {
+			InputQuery:  NewQueryBuilder().AndMatch("name", "j.r[aeiou]"),
+			ExpectedIds: []string{"2"},
+		},
+		"Match is anchored without wildcards.": {
+			InputQuery:  NewQueryBuilder().AndMatch("name", "iro"),
+			ExpectedIds: []string{},
+		},
+		"Metacharacters are literal in Contains.": {
+			InputQuery:  NewQueryBuilder().AndContains("name", "o."),
+			ExpectedIds: []string{},
+		},
+		"Contains.": {
+			InputQuery:  NewQueryBuilder().AndContains("name", "RO"),
+			ExpectedIds: []string{"1", "2", "4"},
+		},
+		"Array element.": {
+			InputQuery:  NewQueryBuilder().AndEqual("tags", "b"),
+			ExpectedIds: []string{"1"},
+		},
+		"Nested field.": {
+			InputQuery:  NewQueryBuilder().AndGreaterThanInt("user.rank", 2),
+			ExpectedIds: []string{"1"},
+		},
+		"Field in array of objects.": {
+			InputQuery:  NewQueryBuilder().AndGreaterThanInt("items.price", 200),
+			ExpectedIds: []string{"4"},
+		},
+		"Missing field.": {
+			InputQuery:  NewQueryBuilder().AndEqual("nothing", "x"),
+			ExpectedIds: []string{},
+		},
+		"All filters.": {
+			InputQuery:  NewQueryBuilder().AndEqual("country", "JP").AndLessThanInt("age", 40),
+			ExpectedIds: []string{"4"},
+		},
+		"Sort by number ascending, missing and strings by type.": {
+			InputQuery:  NewQueryBuilder().SortAsc("user.rank"),
+			ExpectedIds: []string{"3", "4", "2", "1"},
+		},
+		"Sort by mixed types descending.": {
+			InputQuery:  NewQueryBuilder().SortDesc("age"),
+			ExpectedIds: []string{"3", "1", "4", "2"},
+		},
+		"Offset and limit.": {
+			InputQuery:  NewQuery().SortDesc("_createdOn").Offset(1).Limit(2),
+			ExpectedIds: []string{"3", "2"},
+		},
+		"Offset over records.": {
+			InputQuery:  NewQueryBuilder().Offset(10),
+			ExpectedIds: []string{},
+		},
+		"Invalid query.": {
+			InputQuery:    NewQueryBuilder().AndEqual("name", "a,b"),
+			ExpectedError: ErrInvalidQuery,
+		},
+	}
+
+	// run
+	for testCase, param := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			matcher, err := NewMatcher(param.InputQuery)
+			if !errors.Is(err, param.ExpectedError) || (err == nil) != (param.ExpectedError == nil) {
+				t.Fatalf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", err, err, param.ExpectedError, param.ExpectedError)
+			}
+			if err != nil {
+				return
+			}
+			records, err := matcher.ApplyJSON(matcherRecords)
+			if err != nil {
+				t.Fatalf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", err, err, nil, nil)
+			}
+			actual := make([]string, 0)
+			for _, record := range records {
+				object, _ := decodeRecord(record)
+				actual = append(actual, object["_id"].(string))
+			}
+			expected := param.ExpectedIds
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+			}
+		})
+	}
+}
+
+func TestMatcherLimit(t *testing.T) {
+	// test cases
+	testCases := map[string]struct {
+		InputQuery    Querier
+		ExpectedCount int
+	}{
+		"Default limit.": {
+			InputQuery:    NewQueryBuilder(),
+			ExpectedCount: ServerDefaultLimit,
+		},
+		"Max limit.": {
+			InputQuery:    NewQueryBuilder().Limit(5000),
+			ExpectedCount: ServerMaxLimit,
+		},
+		"Limit.": {
+			InputQuery:    NewQueryBuilder().Limit(30),
+			ExpectedCount: 30,
+		},
+	}
+	records := make([]map[string]interface{}, 0)
+	for i := 0; i < 2000; i++ {
+		records = append(records, map[string]interface{}{"index": i, "name": strings.Repeat("a", i%3)})
+	}
+
+	// run
+	for testCase, param := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			matcher, _ := NewMatcher(param.InputQuery)
+			actual := len(matcher.Apply(records))
+			expected := param.ExpectedCount
+			if actual != expected {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+			}
+		})
+	}
+}
+
+func TestMatcherMatch(t *testing.T) {
+	matcher, _ := NewMatcher(NewQueryBuilder().AndWhere("score", OpGreaterThan, 1).AndEqual("name", "taro"))
+	// test cases
+	testCases := map[string]struct {
+		InputRecord map[string]interface{}
+		Expected    bool
+	}{
+		"Go int.":       {InputRecord: map[string]interface{}{"score": 2, "name": "taro"}, Expected: true},
+		"Go uint8.":     {InputRecord: map[string]interface{}{"score": uint8(1), "name": "taro"}, Expected: false},
+		"Go float32.":   {InputRecord: map[string]interface{}{"score": float32(1.5), "name": "taro"}, Expected: true},
+		"Other name.":   {InputRecord: map[string]interface{}{"score": 2, "name": "Taro"}, Expected: false},
+		"Missing name.": {InputRecord: map[string]interface{}{"score": 2}, Expected: false},
+	}
+
+	// run
+	for testCase, param := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			actual := matcher.Match(param.InputRecord)
+			expected := param.Expected
+			if actual != expected {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+			}
+		})
+	}
+}
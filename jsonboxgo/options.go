@@ -0,0 +1,109 @@
+package jsonboxgo
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Option configures DefaultClient on NewClient.
+type Option func(*DefaultClient) error
+
+// WithHTTPClient sets the http.Client used to send requests, http.DefaultClient is used by default.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *DefaultClient) error {
+		if httpClient == nil {
+			return errors.New("jsonboxgo: http client must not be nil")
+		}
+		c.httpClient = httpClient
+		return nil
+	}
+}
+
+// WithTimeout sets the timeout of each request.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *DefaultClient) error {
+		if timeout <= 0 {
+			return fmt.Errorf("jsonboxgo: timeout must be positive: %v", timeout)
+		}
+		c.timeout = timeout
+		return nil
+	}
+}
+
+// WithHeader adds a header sent with every request.
+// The value is appended, so passing the same key twice sends both values.
+func WithHeader(key string, value string) Option {
+	return func(c *DefaultClient) error {
+		if err := validateHeader(key, value); err != nil {
+			return err
+		}
+		c.header.Add(key, value)
+		return nil
+	}
+}
+
+// Replace the single-valued header, the last option wins
+func setHeader(c *DefaultClient, key string, value string) error {
+	if err := validateHeader(key, value); err != nil {
+		return err
+	}
+	c.header.Set(key, value)
+	return nil
+}
+
+func validateHeader(key string, value string) error {
+	if !isHeaderName(key) {
+		return fmt.Errorf("jsonboxgo: invalid header name %q", key)
+	}
+	if strings.ContainsAny(value, "\r\n") {
+		return fmt.Errorf("jsonboxgo: invalid header value for %q", key)
+	}
+	return nil
+}
+
+// WithUserAgent sets the User-Agent header, replacing the previous one.
+func WithUserAgent(userAgent string) Option {
+	return func(c *DefaultClient) error {
+		if strings.TrimSpace(userAgent) == "" {
+			return errors.New("jsonboxgo: user agent must not be empty")
+		}
+		return setHeader(c, "User-Agent", userAgent)
+	}
+}
+
+// WithAPIKey sets the x-api-key header used by protected boxes, replacing the previous one.
+func WithAPIKey(apiKey string) Option {
+	return func(c *DefaultClient) error {
+		if strings.TrimSpace(apiKey) == "" {
+			return errors.New("jsonboxgo: api key must not be empty")
+		}
+		return setHeader(c, "x-api-key", apiKey)
+	}
+}
+
+// WithBasePath sets the path inserted between the base url and the box id, e.g. "/api".
+func WithBasePath(basePath string) Option {
+	return func(c *DefaultClient) error {
+		if strings.ContainsAny(basePath, "?#") {
+			return fmt.Errorf("jsonboxgo: invalid base path %q", basePath)
+		}
+		c.basePath = basePath
+		return nil
+	}
+}
+
+// Report whether name is a valid header field name (RFC 7230 token)
+func isHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if r >= 0x7f || r <= ' ' || strings.ContainsRune(`"(),/:;<=>?@[\]{}`, r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,167 @@
+package jsonboxgo
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewClientValidation(t *testing.T) {
+	// test cases
+	testCases := map[string]struct {
+		InputBaseUrl  string
+		InputBoxId    string
+		InputOptions  []Option
+		ExpectedError bool
+	}{
+		"Valid.": {
+			InputBaseUrl: "https://test.com/",
+			InputBoxId:   "box_xxxxx",
+		},
+		"Base url without scheme.": {
+			InputBaseUrl:  "test.com",
+			InputBoxId:    "box_xxxxx",
+			ExpectedError: true,
+		},
+		"Base url with query.": {
+			InputBaseUrl:  "https://test.com/?a=b",
+			InputBoxId:    "box_xxxxx",
+			ExpectedError: true,
+		},
+		"Empty box id.": {
+			InputBaseUrl:  "https://test.com/",
+			InputBoxId:    "/",
+			ExpectedError: true,
+		},
+		"Box id with invalid character.": {
+			InputBaseUrl:  "https://test.com/",
+			InputBoxId:    "box-xxxxx",
+			ExpectedError: true,
+		},
+		"Nil http client.": {
+			InputBaseUrl:  "https://test.com/",
+			InputBoxId:    "box_xxxxx",
+			InputOptions:  []Option{WithHTTPClient(nil)},
+			ExpectedError: true,
+		},
+		"Negative timeout.": {
+			InputBaseUrl:  "https://test.com/",
+			InputBoxId:    "box_xxxxx",
+			InputOptions:  []Option{WithTimeout(-time.Second)},
+			ExpectedError: true,
+		},
+		"Invalid header name.": {
+			InputBaseUrl:  "https://test.com/",
+			InputBoxId:    "box_xxxxx",
+			InputOptions:  []Option{WithHeader("X Custom", "value")},
+			ExpectedError: true,
+		},
+		"Empty api key.": {
+			InputBaseUrl:  "https://test.com/",
+			InputBoxId:    "box_xxxxx",
+			InputOptions:  []Option{WithAPIKey("")},
+			ExpectedError: true,
+		},
+	}
+
+	// run
+	for testCase, param := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			client, err := NewClient(param.InputBaseUrl, param.InputBoxId, param.InputOptions...)
+			if (err != nil) != param.ExpectedError {
+				t.Errorf("  Failed: err -> %v(%T), expectedError -> %v\n", err, err, param.ExpectedError)
+			}
+			if (client == nil) != param.ExpectedError {
+				t.Errorf("  Failed: client -> %v(%T), expectedError -> %v\n", client, client, param.ExpectedError)
+			}
+		})
+	}
+}
+
+func TestOptionsAppliedToRequest(t *testing.T) {
+	var capturedRequest *http.Request
+	mockHttpClient := NewTestClient(func(req *http.Request) *http.Response {
+		capturedRequest = req
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`[]`)),
+			Header:     make(http.Header),
+		}
+	})
+	client, err := NewClient(
+		"https://test.com",
+		"box_test",
+		WithHTTPClient(mockHttpClient),
+		WithTimeout(time.Second),
+		WithHeader("X-Custom", "custom"),
+		WithUserAgent("jsonbox-go-test"),
+		WithAPIKey("secret"),
+		WithBasePath("/api/"),
+	)
+	if err != nil {
+		t.Fatalf("  Failed: err -> %v(%T)\n", err, err)
+	}
+	if _, err := client.ReadAllWithError("users"); err != nil {
+		t.Fatalf("  Failed: err -> %v(%T)\n", err, err)
+	}
+	expectedHeaders := map[string]string{
+		"X-Custom":     "custom",
+		"User-Agent":   "jsonbox-go-test",
+		"X-Api-Key":    "secret",
+		"Content-Type": "application/json",
+	}
+	for key, expected := range expectedHeaders {
+		actual := capturedRequest.Header.Get(key)
+		if actual != expected {
+			t.Errorf("  Failed: header %v actual -> %v(%T), expected -> %v(%T)\n", key, actual, actual, expected, expected)
+		}
+	}
+	actual := capturedRequest.URL.String()
+	expected := "https://test.com/api/box_test/users"
+	if actual != expected {
+		t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+	}
+	if _, ok := capturedRequest.Context().Deadline(); !ok {
+		t.Errorf("  Failed: request context has no deadline\n")
+	}
+}
+
+func TestOptionsHeaderTwice(t *testing.T) {
+	var capturedRequest *http.Request
+	mockHttpClient := NewTestClient(func(req *http.Request) *http.Response {
+		capturedRequest = req
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`[]`)),
+			Header:     make(http.Header),
+		}
+	})
+	client, _ := NewClient(
+		"https://test.com",
+		"box_test",
+		WithHTTPClient(mockHttpClient),
+		WithUserAgent("default"),
+		WithUserAgent("override"),
+		WithAPIKey("default"),
+		WithAPIKey("override"),
+		WithHeader("X-Custom", "first"),
+		WithHeader("X-Custom", "second"),
+	)
+	if _, err := client.ReadAllWithError("users"); err != nil {
+		t.Fatalf("  Failed: err -> %v(%T)\n", err, err)
+	}
+	expectedHeaders := map[string][]string{
+		"User-Agent": {"override"},
+		"X-Api-Key":  {"override"},
+		"X-Custom":   {"first", "second"},
+	}
+	for key, expected := range expectedHeaders {
+		actual := capturedRequest.Header.Values(key)
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("  Failed: header %v actual -> %v(%T), expected -> %v(%T)\n", key, actual, actual, expected, expected)
+		}
+	}
+}
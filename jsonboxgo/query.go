@@ -0,0 +1,228 @@
+package jsonboxgo
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidQuery is returned when a query can not be represented by jsonbox's query syntax.
+var ErrInvalidQuery = errors.New("jsonboxgo: invalid query")
+
+// Querier is a query accepted by the client, implemented by QueryBuilder and Query.
+type Querier interface {
+	// Build returns the query string starting with "?".
+	Build() string
+	// Err returns the error which makes the query invalid.
+	Err() error
+}
+
+type QueryBuilder interface {
+	Offset(int) QueryBuilder
+	Limit(int) QueryBuilder
+	SortAsc(string) QueryBuilder
+	SortDesc(string) QueryBuilder
+	AndEqual(string, string) QueryBuilder
+	AndGreaterThan(string, string) QueryBuilder
+	AndGreaterThanOrEqual(string, string) QueryBuilder
+	AndLessThan(string, string) QueryBuilder
+	AndLessThanOrEqual(string, string) QueryBuilder
+	AndWhere(string, Operator, interface{}) QueryBuilder
+	AndEqualInt(string, int) QueryBuilder
+	AndEqualFloat(string, float64) QueryBuilder
+	AndEqualBool(string, bool) QueryBuilder
+	AndGreaterThanInt(string, int) QueryBuilder
+	AndGreaterThanOrEqualInt(string, int) QueryBuilder
+	AndLessThanInt(string, int) QueryBuilder
+	AndLessThanOrEqualInt(string, int) QueryBuilder
+	AndGreaterThanFloat(string, float64) QueryBuilder
+	AndGreaterThanOrEqualFloat(string, float64) QueryBuilder
+	AndLessThanFloat(string, float64) QueryBuilder
+	AndLessThanOrEqualFloat(string, float64) QueryBuilder
+	AndCreatedAfter(time.Time) QueryBuilder
+	AndCreatedBefore(time.Time) QueryBuilder
+	AndUpdatedAfter(time.Time) QueryBuilder
+	AndUpdatedBefore(time.Time) QueryBuilder
+	AndStartsWith(string, string) QueryBuilder
+	AndEndsWith(string, string) QueryBuilder
+	AndContains(string, string) QueryBuilder
+	AndMatch(string, string) QueryBuilder
+	Querier
+}
+
+type DefaultQueryBuilder struct {
+	queries []queryParam
+	filters []filter
+	err     error
+}
+
+// queryParam is a parameter other than the filters, e.g. limit=3
+type queryParam struct {
+	name  string
+	value string
+}
+
+// filter is a condition of the "q" parameter, e.g. age:>=40
+type filter struct {
+	field    string
+	operator string
+	value    string
+}
+
+// Create new jsonbox-go QueryBuilder
+func NewQueryBuilder() QueryBuilder {
+	builder := &DefaultQueryBuilder{
+		queries: make([]queryParam, 0),
+		filters: make([]filter, 0),
+	}
+	return builder
+}
+
+func (d *DefaultQueryBuilder) Offset(offset int) QueryBuilder {
+	if offset < 0 {
+		return d.fail(fmt.Errorf("%w: negative offset %d", ErrInvalidQuery, offset))
+	}
+	return d.setQuery("offset", strconv.Itoa(offset))
+}
+
+func (d *DefaultQueryBuilder) Limit(limit int) QueryBuilder {
+	if limit < 0 {
+		return d.fail(fmt.Errorf("%w: negative limit %d", ErrInvalidQuery, limit))
+	}
+	return d.setQuery("limit", strconv.Itoa(limit))
+}
+
+func (d *DefaultQueryBuilder) SortAsc(sort string) QueryBuilder {
+	if err := validateField(sort); err != nil {
+		return d.fail(err)
+	}
+	return d.setQuery("sort", sort)
+}
+
+func (d *DefaultQueryBuilder) SortDesc(sort string) QueryBuilder {
+	if err := validateField(sort); err != nil {
+		return d.fail(err)
+	}
+	return d.setQuery("sort", "-"+sort)
+}
+
+func (d *DefaultQueryBuilder) AndGreaterThan(field string, value string) QueryBuilder {
+	return d.addFilter(field, ":>", value)
+}
+
+func (d *DefaultQueryBuilder) AndLessThan(field string, value string) QueryBuilder {
+	return d.addFilter(field, ":<", value)
+}
+
+func (d *DefaultQueryBuilder) AndGreaterThanOrEqual(field string, value string) QueryBuilder {
+	return d.addFilter(field, ":>=", value)
+}
+
+func (d *DefaultQueryBuilder) AndLessThanOrEqual(field string, value string) QueryBuilder {
+	return d.addFilter(field, ":<=", value)
+}
+
+func (d *DefaultQueryBuilder) AndEqual(field string, value string) QueryBuilder {
+	return d.addFilter(field, ":=", value)
+}
+
+// Set the parameter, the last value wins
+func (d *DefaultQueryBuilder) setQuery(name string, value string) QueryBuilder {
+	for i, query := range d.queries {
+		if query.name == name {
+			d.queries[i].value = value
+			return d
+		}
+	}
+	d.queries = append(d.queries, queryParam{name: name, value: value})
+	return d
+}
+
+func (d *DefaultQueryBuilder) addFilter(name string, operator string, value string) QueryBuilder {
+	if err := validateFilter(name, operator, value); err != nil {
+		return d.fail(err)
+	}
+	d.filters = append(d.filters, filter{field: name, operator: operator, value: value})
+	return d
+}
+
+// Keep the first error, the invalid part is not added to the query
+func (d *DefaultQueryBuilder) fail(err error) QueryBuilder {
+	if d.err == nil {
+		d.err = err
+	}
+	return d
+}
+
+// Build the query string, check Err() before sending it
+func (d *DefaultQueryBuilder) Build() string {
+	params := make([]string, 0, len(d.queries)+1)
+	for _, query := range d.queries {
+		value := query.value
+		if query.name == "sort" {
+			value = wireSort(value)
+		}
+		params = append(params, query.name+"="+escapeQueryValue(value))
+	}
+	if filterParam := d.filterParam(); filterParam != "" {
+		params = append(params, filterParam)
+	}
+	return "?" + strings.Join(params, "&")
+}
+
+// Err returns the first error occurred while building the query.
+func (d *DefaultQueryBuilder) Err() error {
+	return d.err
+}
+
+// Encode the filter, e.g. age:>=40
+func (f filter) String() string {
+	return escapeQueryValue(wireField(f.field)) + f.operator + escapeQueryValue(f.value)
+}
+
+// queryValueReplacer keeps the characters of jsonbox's query syntax readable, and encodes spaces as %20.
+var queryValueReplacer = strings.NewReplacer("%3A", ":", "%3C", "<", "%3E", ">", "%3D", "=", "%2A", "*", "%2C", ",", "+", "%20")
+
+// Percent-encode a query value, "," is never contained since it is rejected by validation
+func escapeQueryValue(value string) string {
+	return queryValueReplacer.Replace(url.QueryEscape(value))
+}
+
+// Field names must not contain the separators of the "q" parameter, and must be a path jsonbox can address
+func validateField(field string) error {
+	if strings.TrimSpace(field) == "" {
+		return fmt.Errorf("%w: empty field name", ErrInvalidQuery)
+	}
+	if strings.ContainsAny(field, ",:") {
+		return fmt.Errorf("%w: field name %q must not contain ',' or ':'", ErrInvalidQuery, field)
+	}
+	if strings.HasPrefix(field, "-") {
+		return fmt.Errorf("%w: field name %q must not start with '-'", ErrInvalidQuery, field)
+	}
+	if _, err := serverField(field); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Validate the filter can be represented, e.g. "a" ":" "=b" can not be distinguished from "a" ":=" "b".
+// A ":" in the value is kept, because the field ends at the first ":" and timestamps contain ":".
+func validateFilter(field string, operator string, value string) error {
+	if err := validateField(field); err != nil {
+		return err
+	}
+	if value == "" {
+		return fmt.Errorf("%w: empty value of %q", ErrInvalidQuery, field)
+	}
+	if strings.Contains(value, ",") {
+		return fmt.Errorf("%w: value %q of %q must not contain ','", ErrInvalidQuery, value, field)
+	}
+	if (operator == string(OpMatch) && strings.ContainsAny(value[:1], "=<>")) ||
+		((operator == string(OpGreaterThan) || operator == string(OpLessThan)) && value[0] == '=') {
+		return fmt.Errorf("%w: value %q of %q must not start with %q after %q", ErrInvalidQuery, value, field, value[:1], operator)
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package jsonboxgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultPageSize is the number of records read by one request when paging through records.
+const DefaultPageSize = 100
+
+// BulkResult is the result of DeleteByQuery and UpdateByQuery.
+type BulkResult struct {
+	// Count is the number of affected records, or the number of records which would be affected in dry run.
+	Count int
+	// Records are the affected records, filled in dry run and by UpdateByQuery.
+	Records [][]byte
+	DryRun  bool
+}
+
+// BulkOption configures DeleteByQuery and UpdateByQuery.
+type BulkOption func(*bulkConfig)
+
+type bulkConfig struct {
+	dryRun   bool
+	pageSize int
+}
+
+// DryRun only reports the records which would be affected.
+func DryRun() BulkOption {
+	return func(c *bulkConfig) {
+		c.dryRun = true
+	}
+}
+
+// BulkPageSize sets the number of records read by one request, DefaultPageSize is used by default.
+// It is capped at ServerMaxLimit, since jsonbox does not respond more records at once.
+func BulkPageSize(pageSize int) BulkOption {
+	return func(c *bulkConfig) {
+		if pageSize > ServerMaxLimit {
+			pageSize = ServerMaxLimit
+		}
+		if pageSize > 0 {
+			c.pageSize = pageSize
+		}
+	}
+}
+
+// MutateFunc modifies a matched record in place before it is updated.
+type MutateFunc func(record map[string]interface{}) error
+
+// removedCountPattern extracts the count from {"message":"3 Records removed."}
+var removedCountPattern = regexp.MustCompile(`^\s*(\d+)`)
+
+// Delete records matched by query
+func (c DefaultClient) DeleteByQuery(collection string, query Querier, opts ...BulkOption) (BulkResult, error) {
+	return c.DeleteByQueryContext(context.Background(), collection, query, opts...)
+}
+
+// Update records matched by query
+func (c DefaultClient) UpdateByQuery(collection string, query Querier, mutate MutateFunc, opts ...BulkOption) (BulkResult, error) {
+	return c.UpdateByQueryContext(context.Background(), collection, query, mutate, opts...)
+}
+
+// Delete records matched by the filters of query with context.
+// Offset, limit and sort of query are ignored, all the matched records are removed.
+func (c DefaultClient) DeleteByQueryContext(ctx context.Context, collection string, query Querier, opts ...BulkOption) (BulkResult, error) {
+	config := newBulkConfig(opts)
+	if err := query.Err(); err != nil {
+		return BulkResult{}, err
+	}
+	filterQuery, err := filterOnlyQuery(query.Build())
+	if err != nil {
+		return BulkResult{}, err
+	}
+	if filterQuery == "" {
+		return BulkResult{}, errors.New("jsonboxgo: DeleteByQuery requires at least one filter")
+	}
+	if config.dryRun {
+		records, err := c.readAllPages(ctx, collection, filterQuery, config.pageSize)
+		if err != nil {
+			return BulkResult{}, err
+		}
+		return BulkResult{Count: len(records), Records: records, DryRun: true}, nil
+	}
+	body, err := c.do(ctx, "DELETE", collection, "", "?"+filterQuery, nil)
+	if err != nil {
+		return BulkResult{}, err
+	}
+	message := parseMessage(200, body)
+	matched := removedCountPattern.FindStringSubmatch(message)
+	if matched == nil {
+		return BulkResult{}, fmt.Errorf("jsonboxgo: unexpected response of DeleteByQuery: %s", string(body))
+	}
+	count, _ := strconv.Atoi(matched[1])
+	return BulkResult{Count: count}, nil
+}
+
+// Update records matched by the filters of query with context.
+// All the matched records are read first, then each record is modified by mutate and PUT one by one.
+// Offset, limit and sort of query are ignored. Fields starting with "_" are not sent,
+// the returned records keep "_id" and the timestamps read before the update.
+func (c DefaultClient) UpdateByQueryContext(ctx context.Context, collection string, query Querier, mutate MutateFunc, opts ...BulkOption) (BulkResult, error) {
+	config := newBulkConfig(opts)
+	if err := query.Err(); err != nil {
+		return BulkResult{}, err
+	}
+	filterQuery, err := filterOnlyQuery(query.Build())
+	if err != nil {
+		return BulkResult{}, err
+	}
+	if filterQuery == "" {
+		return BulkResult{}, errors.New("jsonboxgo: UpdateByQuery requires at least one filter")
+	}
+	records, err := c.readAllPages(ctx, collection, filterQuery, config.pageSize)
+	if err != nil {
+		return BulkResult{}, err
+	}
+	if config.dryRun {
+		return BulkResult{Count: len(records), Records: records, DryRun: true}, nil
+	}
+	result := BulkResult{Records: make([][]byte, 0, len(records))}
+	for _, record := range records {
+		var object map[string]interface{}
+		if err := json.Unmarshal(record, &object); err != nil {
+			return result, fmt.Errorf("jsonboxgo: json.Unmarshal() failed: %w", err)
+		}
+		recordId, _ := object["_id"].(string)
+		if recordId == "" {
+			return result, fmt.Errorf("jsonboxgo: record without _id: %s", string(record))
+		}
+		meta := make(map[string]interface{})
+		for key, value := range object {
+			if strings.HasPrefix(key, "_") {
+				meta[key] = value
+			}
+		}
+		if err := mutate(object); err != nil {
+			return result, fmt.Errorf("jsonboxgo: mutate record %q failed: %w", recordId, err)
+		}
+		for key := range object {
+			if strings.HasPrefix(key, "_") {
+				delete(object, key)
+			}
+		}
+		if _, err := c.do(ctx, "PUT", collection, recordId, "", object); err != nil {
+			return result, err
+		}
+		for key, value := range meta {
+			object[key] = value
+		}
+		updated, _ := json.Marshal(object)
+		result.Count++
+		result.Records = append(result.Records, updated)
+	}
+	return result, nil
+}
+
+// Read every page of the query, sorted by _createdOn so the pages are stable
+func (c DefaultClient) readAllPages(ctx context.Context, collection string, filterQuery string, pageSize int) ([][]byte, error) {
+	records := make([][]byte, 0)
+	for offset := 0; ; offset += pageSize {
+		body, err := c.do(ctx, "GET", collection, "", pagedQuery(filterQuery, "_createdOn", offset, pageSize), nil)
+		if err != nil {
+			return nil, err
+		}
+		var page []json.RawMessage
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("jsonboxgo: json.Unmarshal() failed: %w", err)
+		}
+		for _, record := range page {
+			records = append(records, record)
+		}
+		if len(page) < pageSize {
+			return records, nil
+		}
+	}
+}
+
+func newBulkConfig(opts []BulkOption) bulkConfig {
+	config := bulkConfig{pageSize: DefaultPageSize}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return config
+}
+
+// Keep only the filter parameter of the built query, e.g. "?limit=3&q=age:>40" -> "q=age:>40"
+func filterOnlyQuery(query string) (string, error) {
+	builder, err := parseQueryString(query)
+	if err != nil {
+		return "", err
+	}
+	return builder.filterParam(), nil
+}
+
+// Build the query string of a page
+func pagedQuery(filterQuery string, sort string, offset int, limit int) string {
+	params := []string{"offset=" + strconv.Itoa(offset), "limit=" + strconv.Itoa(limit)}
+	if sort != "" {
+		params = append(params, "sort="+sort)
+	}
+	if filterQuery != "" {
+		params = append(params, filterQuery)
+	}
+	return "?" + strings.Join(params, "&")
+}
@@ -0,0 +1,218 @@
+package jsonboxgo
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestDeleteByQuery(t *testing.T) {
+	// test cases
+	testCases := map[string]struct {
+		InputQuery          QueryBuilder
+		InputOptions        []BulkOption
+		InputResponses      []TestResponse
+		ExpectedCount       int
+		ExpectedRequestUrls []string
+		ExpectedError       bool
+	}{
+		"Deleted.": {
+			InputQuery:          NewQueryBuilder().Limit(1).AndEqual("name", "taro"),
+			InputResponses:      []TestResponse{{StatusCode: 200, Body: `{"message":"3 Records removed."}`}},
+			ExpectedCount:       3,
+			ExpectedRequestUrls: []string{"DELETE https://test.com/box_test/users?q=name:=taro"},
+		},
+		"Dry run.": {
+			InputQuery:   NewQueryBuilder().AndGreaterThan("age", "20"),
+			InputOptions: []BulkOption{DryRun(), BulkPageSize(2)},
+			InputResponses: []TestResponse{
+				{StatusCode: 200, Body: `[{"_id":"id001"},{"_id":"id002"}]`},
+				{StatusCode: 200, Body: `[{"_id":"id003"}]`},
+			},
+			ExpectedCount: 3,
+			ExpectedRequestUrls: []string{
+				"GET https://test.com/box_test/users?offset=0&limit=2&sort=_createdOn&q=age:>20",
+				"GET https://test.com/box_test/users?offset=2&limit=2&sort=_createdOn&q=age:>20",
+			},
+		},
+		"No filter.": {
+			InputQuery:          NewQueryBuilder().Limit(1),
+			InputResponses:      []TestResponse{},
+			ExpectedRequestUrls: []string{},
+			ExpectedError:       true,
+		},
+	}
+
+	// run
+	for testCase, param := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			requests := make([]*http.Request, 0)
+			client := NewTestJsonboxClient(CreateNewSequenceTestClient(param.InputResponses, &requests))
+			result, err := client.DeleteByQuery("users", param.InputQuery, param.InputOptions...)
+			if (err != nil) != param.ExpectedError {
+				t.Errorf("  Failed: err -> %v(%T), expectedError -> %v\n", err, err, param.ExpectedError)
+			}
+			actual := result.Count
+			expected := param.ExpectedCount
+			if actual != expected {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+			}
+			if len(requests) != len(param.ExpectedRequestUrls) {
+				t.Fatalf("  Failed: requestCount -> %v, expected -> %v\n", len(requests), len(param.ExpectedRequestUrls))
+			}
+			for i, req := range requests {
+				actualUrl := req.Method + " " + req.URL.String()
+				if actualUrl != param.ExpectedRequestUrls[i] {
+					t.Errorf("  Failed: actual -> %v, expected -> %v\n", actualUrl, param.ExpectedRequestUrls[i])
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateByQuery(t *testing.T) {
+	requests := make([]*http.Request, 0)
+	requestBodies := make([]string, 0)
+	mockHttpClient := CreateNewSequenceTestClient([]TestResponse{
+		{StatusCode: 200, Body: `[{"_id":"id001","name":"taro","age":20,"_createdOn":"2020-04-26T16:26:13.935Z"},{"_id":"id002","name":"jiro","age":30}]`},
+		{StatusCode: 200, Body: `{"message":"Record updated."}`},
+		{StatusCode: 200, Body: `{"message":"Record updated."}`},
+	}, &requests)
+	transport := mockHttpClient.Transport
+	mockHttpClient.Transport = RoundTripErrorFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Body != nil {
+			body, _ := ioutil.ReadAll(req.Body)
+			requestBodies = append(requestBodies, string(body))
+		}
+		return transport.RoundTrip(req)
+	})
+	client := NewTestJsonboxClient(mockHttpClient)
+	result, err := client.UpdateByQuery("users", NewQueryBuilder().AndGreaterThanOrEqual("age", "20"), func(record map[string]interface{}) error {
+		record["age"] = record["age"].(float64) + 1
+		return nil
+	}, BulkPageSize(10))
+	if err != nil || result.Count != 2 || result.DryRun {
+		t.Fatalf("  Failed: result -> %+v, err -> %v\n", result, err)
+	}
+	expectedUrls := []string{
+		"GET https://test.com/box_test/users?offset=0&limit=10&sort=_createdOn&q=age:>=20",
+		"PUT https://test.com/box_test/users/id001",
+		"PUT https://test.com/box_test/users/id002",
+	}
+	for i, req := range requests {
+		actual := req.Method + " " + req.URL.String()
+		if actual != expectedUrls[i] {
+			t.Errorf("  Failed: actual -> %v, expected -> %v\n", actual, expectedUrls[i])
+		}
+	}
+	expectedBodies := []string{`{"age":21,"name":"taro"}`, `{"age":31,"name":"jiro"}`}
+	for i, expected := range expectedBodies {
+		actual := requestBodies[i]
+		if actual != expected {
+			t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+		}
+	}
+	expectedRecords := []string{
+		`{"_createdOn":"2020-04-26T16:26:13.935Z","_id":"id001","age":21,"name":"taro"}`,
+		`{"_id":"id002","age":31,"name":"jiro"}`,
+	}
+	for i, expected := range expectedRecords {
+		actual := string(result.Records[i])
+		if actual != expected {
+			t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+		}
+	}
+}
+
+func TestUpdateByQueryInvalid(t *testing.T) {
+	// test cases
+	testCases := map[string]struct {
+		InputQuery           QueryBuilder
+		InputResponses       []TestResponse
+		ExpectedCount        int
+		ExpectedRequestCount int
+	}{
+		"No filter.": {
+			InputQuery:           NewQueryBuilder().Limit(1),
+			InputResponses:       []TestResponse{},
+			ExpectedRequestCount: 0,
+		},
+		"Record without _id.": {
+			InputQuery: NewQueryBuilder().AndEqual("name", "taro"),
+			InputResponses: []TestResponse{
+				{StatusCode: 200, Body: `[{"_id":"id001","name":"taro"},{"name":"taro"}]`},
+				{StatusCode: 200, Body: `{"message":"Record updated."}`},
+			},
+			ExpectedCount:        1,
+			ExpectedRequestCount: 2,
+		},
+	}
+
+	// run
+	for testCase, param := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			requests := make([]*http.Request, 0)
+			client := NewTestJsonboxClient(CreateNewSequenceTestClient(param.InputResponses, &requests))
+			result, err := client.UpdateByQuery("users", param.InputQuery, func(record map[string]interface{}) error {
+				record["name"] = "jiro"
+				return nil
+			})
+			if err == nil {
+				t.Errorf("  Failed: err -> %v(%T), expected -> an error\n", err, err)
+			}
+			actual := result.Count
+			expected := param.ExpectedCount
+			if actual != expected {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+			}
+			if len(requests) != param.ExpectedRequestCount {
+				t.Errorf("  Failed: requestCount -> %v, expected -> %v\n", len(requests), param.ExpectedRequestCount)
+			}
+		})
+	}
+}
+
+func TestUpdateByQueryDryRun(t *testing.T) {
+	requests := make([]*http.Request, 0)
+	client := NewTestJsonboxClient(CreateNewSequenceTestClient([]TestResponse{
+		{StatusCode: 200, Body: `[{"_id":"id001","name":"taro"}]`},
+	}, &requests))
+	result, err := client.UpdateByQuery("users", NewQueryBuilder().AndEqual("name", "taro"), func(record map[string]interface{}) error {
+		t.Errorf("  Failed: mutate called in dry run\n")
+		return nil
+	}, DryRun())
+	if err != nil || result.Count != 1 || !result.DryRun || string(result.Records[0]) != `{"_id":"id001","name":"taro"}` || len(requests) != 1 {
+		t.Errorf("  Failed: result -> %+v, err -> %v, requestCount -> %v\n", result, err, len(requests))
+	}
+}
+
+func TestBulkPageSizeCapped(t *testing.T) {
+	records := make([]string, 0, ServerMaxLimit)
+	for i := 0; i < ServerMaxLimit; i++ {
+		records = append(records, fmt.Sprintf(`{"_id":"id%04d"}`, i))
+	}
+	requests := make([]*http.Request, 0)
+	client := NewTestJsonboxClient(CreateNewSequenceTestClient([]TestResponse{
+		{StatusCode: 200, Body: "[" + strings.Join(records, ",") + "]"},
+		{StatusCode: 200, Body: `[{"_id":"id1000"}]`},
+	}, &requests))
+	result, err := client.UpdateByQuery("users", NewQueryBuilder().AndEqual("name", "taro"), func(record map[string]interface{}) error {
+		return nil
+	}, BulkPageSize(2000), DryRun())
+	actual := result.Count
+	expected := ServerMaxLimit + 1
+	if err != nil || actual != expected {
+		t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T), err -> %v\n", actual, actual, expected, expected, err)
+	}
+	expectedUrls := []string{
+		"https://test.com/box_test/users?offset=0&limit=1000&sort=_createdOn&q=name:=taro",
+		"https://test.com/box_test/users?offset=1000&limit=1000&sort=_createdOn&q=name:=taro",
+	}
+	for i, req := range requests {
+		if actualUrl := req.URL.String(); actualUrl != expectedUrls[i] {
+			t.Errorf("  Failed: actual -> %v, expected -> %v\n", actualUrl, expectedUrls[i])
+		}
+	}
+}
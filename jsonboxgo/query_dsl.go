@@ -0,0 +1,379 @@
+package jsonboxgo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ErrUnsupportedQuery is returned when a query uses a construct jsonbox can not filter server-side, e.g. OR and NOT.
+var ErrUnsupportedQuery = errors.New("jsonboxgo: query is not supported by jsonbox")
+
+// SyntaxError is returned by CompileQuery, Column is the 1-based column where compiling failed.
+// Err wraps ErrInvalidQuery or ErrUnsupportedQuery.
+type SyntaxError struct {
+	Column int
+	Err    error
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%v (column %d)", e.Err, e.Column)
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// CompileQuery compiles a human readable query into a QueryBuilder, e.g.
+//
+//	age >= 40 and country = "JP" and name like "taro*" order by -age limit 3 offset 1
+//
+// Conditions are joined by "and" and compare a field with a string, a number, true or false
+// using =, >, >=, <, <= or like (a pattern of AndMatch). Fields colliding with keywords are quoted by backticks.
+// "or", "not", "!=" and grouping are rejected with ErrUnsupportedQuery.
+func CompileQuery(query string) (QueryBuilder, error) {
+	tokens, err := lexQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	parser := &queryParser{tokens: tokens, builder: NewQueryBuilder()}
+	if err := parser.parse(); err != nil {
+		return nil, err
+	}
+	return parser.builder, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenQuotedIdent
+	tokenString
+	tokenNumber
+	tokenSymbol
+)
+
+type token struct {
+	kind   tokenKind
+	text   string
+	column int
+}
+
+// Keywords are case-insensitive and can not be used as bare field names
+var queryKeywords = map[string]bool{
+	"and": true, "or": true, "not": true, "in": true, "like": true, "true": true, "false": true,
+	"order": true, "by": true, "asc": true, "desc": true, "limit": true, "offset": true,
+}
+
+func (t token) is(keyword string) bool {
+	return t.kind == tokenIdent && strings.EqualFold(t.text, keyword)
+}
+
+func (t token) isKeyword() bool {
+	return t.kind == tokenIdent && queryKeywords[strings.ToLower(t.text)]
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of query"
+	case tokenString:
+		return strconv.Quote(t.text)
+	case tokenQuotedIdent:
+		return "`" + t.text + "`"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+func newSyntaxError(column int, sentinel error, format string, args ...interface{}) *SyntaxError {
+	return &SyntaxError{Column: column, Err: fmt.Errorf("%w: "+format, append([]interface{}{sentinel}, args...)...)}
+}
+
+// Split the query into tokens, columns are counted in runes
+func lexQuery(query string) ([]token, error) {
+	runes := []rune(query)
+	tokens := make([]token, 0)
+	for i := 0; ; {
+		for i < len(runes) && unicode.IsSpace(runes[i]) {
+			i++
+		}
+		column := i + 1
+		if i >= len(runes) {
+			return append(tokens, token{kind: tokenEOF, column: column}), nil
+		}
+		r := runes[i]
+		switch {
+		case r == '"':
+			text, end, err := lexString(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, text: text, column: column})
+			i = end
+		case r == '`':
+			end := i + 1
+			for end < len(runes) && runes[end] != '`' {
+				end++
+			}
+			if end >= len(runes) {
+				return nil, newSyntaxError(column, ErrInvalidQuery, "unterminated quoted field")
+			}
+			tokens = append(tokens, token{kind: tokenQuotedIdent, text: string(runes[i+1 : end]), column: column})
+			i = end + 1
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			end := lexNumber(runes, i)
+			tokens = append(tokens, token{kind: tokenNumber, text: string(runes[i:end]), column: column})
+			i = end
+		case unicode.IsLetter(r) || r == '_':
+			end := i + 1
+			for end < len(runes) && (unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end]) || runes[end] == '_' || runes[end] == '.') {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: string(runes[i:end]), column: column})
+			i = end
+		default:
+			symbol := string(r)
+			if i+1 < len(runes) {
+				switch two := string(runes[i : i+2]); two {
+				case ">=", "<=", "!=", "<>", "==":
+					symbol = two
+				}
+			}
+			if !strings.Contains("=<>!-+(),", string(r)) {
+				return nil, newSyntaxError(column, ErrInvalidQuery, "unexpected character %q", r)
+			}
+			tokens = append(tokens, token{kind: tokenSymbol, text: symbol, column: column})
+			i += len([]rune(symbol))
+		}
+	}
+}
+
+// Read a double-quoted string, \" and \\ are unescaped and other escapes are kept as is for wildcard patterns
+func lexString(runes []rune, start int) (string, int, error) {
+	var builder strings.Builder
+	for i := start + 1; i < len(runes); i++ {
+		switch runes[i] {
+		case '"':
+			return builder.String(), i + 1, nil
+		case '\\':
+			if i+1 < len(runes) && (runes[i+1] == '"' || runes[i+1] == '\\') {
+				i++
+			} else if i+1 < len(runes) {
+				builder.WriteRune(runes[i])
+				i++
+			}
+		}
+		if i < len(runes) {
+			builder.WriteRune(runes[i])
+		}
+	}
+	return "", 0, newSyntaxError(start+1, ErrInvalidQuery, "unterminated string")
+}
+
+// Read a number such as 40, -1.5 or 1e3 and return its end
+func lexNumber(runes []rune, start int) int {
+	i := start
+	if runes[i] == '-' {
+		i++
+	}
+	digits := func() {
+		for i < len(runes) && unicode.IsDigit(runes[i]) {
+			i++
+		}
+	}
+	digits()
+	if i+1 < len(runes) && runes[i] == '.' && unicode.IsDigit(runes[i+1]) {
+		i++
+		digits()
+	}
+	if i < len(runes) && (runes[i] == 'e' || runes[i] == 'E') {
+		exponent := i + 1
+		if exponent < len(runes) && (runes[exponent] == '+' || runes[exponent] == '-') {
+			exponent++
+		}
+		if exponent < len(runes) && unicode.IsDigit(runes[exponent]) {
+			i = exponent
+			digits()
+		}
+	}
+	return i
+}
+
+type queryParser struct {
+	tokens  []token
+	pos     int
+	builder QueryBuilder
+}
+
+func (p *queryParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// query := [condition {"and" condition}] ["order" "by" sort] ["limit" number] ["offset" number]
+// The clauses after the conditions are accepted in any order.
+func (p *queryParser) parse() error {
+	if t := p.peek(); t.kind != tokenEOF && !t.is("order") && !t.is("limit") && !t.is("offset") {
+		for {
+			if err := p.parseCondition(); err != nil {
+				return err
+			}
+			if !p.peek().is("and") {
+				break
+			}
+			p.next()
+		}
+	}
+	seen := map[string]bool{}
+	for {
+		t := p.next()
+		clause := strings.ToLower(t.text)
+		switch {
+		case t.kind == tokenEOF:
+			return nil
+		case t.is("or"):
+			return newSyntaxError(t.column, ErrUnsupportedQuery, "\"or\" can not be filtered by jsonbox")
+		case t.is("order") || t.is("limit") || t.is("offset"):
+			if seen[clause] {
+				return newSyntaxError(t.column, ErrInvalidQuery, "duplicated %q", clause)
+			}
+			seen[clause] = true
+		default:
+			return newSyntaxError(t.column, ErrInvalidQuery, "expected \"and\", \"order by\", \"limit\", \"offset\" or end of query, found %v", t)
+		}
+		var err error
+		switch clause {
+		case "order":
+			err = p.parseOrderBy()
+		case "limit":
+			err = p.parseNumberClause(p.builder.Limit)
+		case "offset":
+			err = p.parseNumberClause(p.builder.Offset)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// condition := field ("=" | ">" | ">=" | "<" | "<=" | "like") value
+func (p *queryParser) parseCondition() error {
+	t := p.next()
+	if t.is("not") {
+		return newSyntaxError(t.column, ErrUnsupportedQuery, "\"not\" can not be filtered by jsonbox")
+	}
+	if t.kind == tokenSymbol && t.text == "(" {
+		return newSyntaxError(t.column, ErrUnsupportedQuery, "grouping can not be filtered by jsonbox")
+	}
+	field, err := p.field(t)
+	if err != nil {
+		return err
+	}
+
+	t = p.next()
+	var operator Operator
+	switch {
+	case t.kind == tokenSymbol && (t.text == "=" || t.text == "=="):
+		operator = OpEqual
+	case t.kind == tokenSymbol && t.text == ">":
+		operator = OpGreaterThan
+	case t.kind == tokenSymbol && t.text == ">=":
+		operator = OpGreaterThanOrEqual
+	case t.kind == tokenSymbol && t.text == "<":
+		operator = OpLessThan
+	case t.kind == tokenSymbol && t.text == "<=":
+		operator = OpLessThanOrEqual
+	case t.is("like"):
+		operator = OpMatch
+	case t.kind == tokenSymbol && (t.text == "!=" || t.text == "<>"), t.is("not"), t.is("in"):
+		return newSyntaxError(t.column, ErrUnsupportedQuery, "%v can not be filtered by jsonbox", t)
+	default:
+		return newSyntaxError(t.column, ErrInvalidQuery, "expected operator after %q, found %v", field, t)
+	}
+
+	t = p.next()
+	var value interface{}
+	switch {
+	case t.kind == tokenString:
+		value = t.text
+	case t.kind == tokenNumber:
+		value = json.Number(t.text)
+	case t.is("true"), t.is("false"):
+		value = strings.EqualFold(t.text, "true")
+	default:
+		return newSyntaxError(t.column, ErrInvalidQuery, "expected value of %q, found %v", field, t)
+	}
+	if operator == OpMatch && t.kind != tokenString {
+		return newSyntaxError(t.column, ErrInvalidQuery, "\"like\" expects a string pattern, found %v", t)
+	}
+	if _, err := newFilter(field, operator, value); err != nil {
+		return &SyntaxError{Column: t.column, Err: err}
+	}
+	p.builder.AndWhere(field, operator, value)
+	return nil
+}
+
+// sort := ["-" | "+"] field ["asc" | "desc"]
+func (p *queryParser) parseOrderBy() error {
+	if t := p.next(); !t.is("by") {
+		return newSyntaxError(t.column, ErrInvalidQuery, "expected \"by\" after \"order\", found %v", t)
+	}
+	t := p.next()
+	signed, descending := false, false
+	if t.kind == tokenSymbol && (t.text == "-" || t.text == "+") {
+		signed, descending = true, t.text == "-"
+		t = p.next()
+	}
+	field, err := p.field(t)
+	if err != nil {
+		return err
+	}
+	if direction := p.peek(); direction.is("asc") || direction.is("desc") {
+		if signed {
+			return newSyntaxError(direction.column, ErrInvalidQuery, "sort direction is given by both sign and %v", direction)
+		}
+		p.next()
+		descending = direction.is("desc")
+	}
+	if t := p.peek(); t.kind == tokenSymbol && t.text == "," {
+		return newSyntaxError(t.column, ErrUnsupportedQuery, "jsonbox sorts by one field only")
+	}
+	if descending {
+		p.builder.SortDesc(field)
+	} else {
+		p.builder.SortAsc(field)
+	}
+	return nil
+}
+
+func (p *queryParser) parseNumberClause(set func(int) QueryBuilder) error {
+	t := p.next()
+	number, err := strconv.Atoi(t.text)
+	if t.kind != tokenNumber || err != nil || number < 0 {
+		return newSyntaxError(t.column, ErrInvalidQuery, "expected non-negative integer, found %v", t)
+	}
+	set(number)
+	return nil
+}
+
+// A bare field must not be a keyword, a quoted field can be anything jsonbox accepts
+func (p *queryParser) field(t token) (string, error) {
+	if t.kind != tokenQuotedIdent && (t.kind != tokenIdent || t.isKeyword()) {
+		return "", newSyntaxError(t.column, ErrInvalidQuery, "expected field, found %v", t)
+	}
+	if err := validateField(t.text); err != nil {
+		return "", &SyntaxError{Column: t.column, Err: err}
+	}
+	return t.text, nil
+}
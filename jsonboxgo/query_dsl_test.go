@@ -0,0 +1,166 @@
+package jsonboxgo
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCompileQuery(t *testing.T) {
+	// test cases
+	testCases := map[string]struct {
+		InputQuery     string
+		ExpectedQuery  string
+		ExpectedError  error
+		ExpectedColumn int
+	}{
+		"Empty.": {
+			InputQuery:    "  ",
+			ExpectedQuery: "?",
+		},
+		"All clauses.": {
+			InputQuery:    `age >= 40 and country = "JP" order by -age limit 3 offset 1`,
+			ExpectedQuery: "?sort=-age&limit=3&offset=1&q=age:>=40,country:=JP",
+		},
+		"Clauses only.": {
+			InputQuery:    "offset 10 limit 5",
+			ExpectedQuery: "?offset=10&limit=5",
+		},
+		"Keywords are case-insensitive.": {
+			InputQuery:    `Age > 20 AND name LIKE "taro*" ORDER BY name DESC`,
+			ExpectedQuery: "?sort=-name&q=Age:>20,name:taro*",
+		},
+		"All operators.": {
+			InputQuery:    `a = 1 and b == -2 and c > 1.5 and d >= 1e3 and e < "m" and f <= 0 and g = true and h = FALSE`,
+			ExpectedQuery: "?q=a:=1,b:=-2,c:>1.5,d:>=1e3,e:<m,f:<=0,g:=true,h:=false",
+		},
+		"Sort ascending.": {
+			InputQuery:    "order by +age",
+			ExpectedQuery: "?sort=age",
+		},
+		"Nested and quoted field.": {
+			InputQuery:    "user.age > 20 and `limit` = 3 order by `order` asc",
+			ExpectedQuery: "?sort=order&q=user.age:>20,limit:=3",
+		},
+		"Escaped string.": {
+			InputQuery:    `memo = "say \"hi\" & bye" and path like "\*\\\\*"`,
+			ExpectedQuery: `?q=memo:=say%20%22hi%22%20%26%20bye,path:%5C*%5C%5C*`,
+		},
+		"Columns count runes.": {
+			InputQuery:     `名前 = "太郎" or age > 1`,
+			ExpectedError:  ErrUnsupportedQuery,
+			ExpectedColumn: 11,
+		},
+		"Or is rejected.": {
+			InputQuery:     `age > 40 or country = "JP"`,
+			ExpectedError:  ErrUnsupportedQuery,
+			ExpectedColumn: 10,
+		},
+		"Not is rejected.": {
+			InputQuery:     `age > 40 and not country = "JP"`,
+			ExpectedError:  ErrUnsupportedQuery,
+			ExpectedColumn: 14,
+		},
+		"Not equal is rejected.": {
+			InputQuery:     `country != "JP"`,
+			ExpectedError:  ErrUnsupportedQuery,
+			ExpectedColumn: 9,
+		},
+		"Grouping is rejected.": {
+			InputQuery:     `(age > 40)`,
+			ExpectedError:  ErrUnsupportedQuery,
+			ExpectedColumn: 1,
+		},
+		"Multiple sort keys are rejected.": {
+			InputQuery:     `order by age, name`,
+			ExpectedError:  ErrUnsupportedQuery,
+			ExpectedColumn: 13,
+		},
+		"Missing value.": {
+			InputQuery:     `age >= `,
+			ExpectedError:  ErrInvalidQuery,
+			ExpectedColumn: 8,
+		},
+		"Missing operator.": {
+			InputQuery:     `age 40`,
+			ExpectedError:  ErrInvalidQuery,
+			ExpectedColumn: 5,
+		},
+		"Keyword as field.": {
+			InputQuery:     `limit = 3 and age > 1`,
+			ExpectedError:  ErrInvalidQuery,
+			ExpectedColumn: 7,
+		},
+		"Unterminated string.": {
+			InputQuery:     `name = "taro`,
+			ExpectedError:  ErrInvalidQuery,
+			ExpectedColumn: 8,
+		},
+		"Unexpected character.": {
+			InputQuery:     `age > 40 ; drop`,
+			ExpectedError:  ErrInvalidQuery,
+			ExpectedColumn: 10,
+		},
+		"Bool with range operator.": {
+			InputQuery:     `active > true`,
+			ExpectedError:  ErrInvalidQuery,
+			ExpectedColumn: 10,
+		},
+		"Like with number.": {
+			InputQuery:     `name like 1`,
+			ExpectedError:  ErrInvalidQuery,
+			ExpectedColumn: 11,
+		},
+		"Comma in value.": {
+			InputQuery:     `name = "a,b"`,
+			ExpectedError:  ErrInvalidQuery,
+			ExpectedColumn: 8,
+		},
+		"Negative limit.": {
+			InputQuery:     `limit -1`,
+			ExpectedError:  ErrInvalidQuery,
+			ExpectedColumn: 7,
+		},
+		"Duplicated clause.": {
+			InputQuery:     `limit 1 limit 2`,
+			ExpectedError:  ErrInvalidQuery,
+			ExpectedColumn: 9,
+		},
+		"Missing by.": {
+			InputQuery:     `order age`,
+			ExpectedError:  ErrInvalidQuery,
+			ExpectedColumn: 7,
+		},
+		"Sign and direction.": {
+			InputQuery:     `order by -age desc`,
+			ExpectedError:  ErrInvalidQuery,
+			ExpectedColumn: 15,
+		},
+	}
+
+	// run
+	for testCase, param := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			builder, err := CompileQuery(param.InputQuery)
+			if !errors.Is(err, param.ExpectedError) || (err == nil) != (param.ExpectedError == nil) {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", err, err, param.ExpectedError, param.ExpectedError)
+			}
+			if err != nil {
+				var syntaxError *SyntaxError
+				if !errors.As(err, &syntaxError) {
+					t.Fatalf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", err, err, syntaxError, syntaxError)
+				}
+				actual := syntaxError.Column
+				expected := param.ExpectedColumn
+				if actual != expected {
+					t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+				}
+				return
+			}
+			actual := builder.Build()
+			expected := param.ExpectedQuery
+			if actual != expected {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+			}
+		})
+	}
+}
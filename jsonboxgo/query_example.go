@@ -0,0 +1,128 @@
+package jsonboxgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	timeType   = reflect.TypeOf(time.Time{})
+	numberType = reflect.TypeOf(json.Number(""))
+)
+
+// QueryFromExample creates a QueryBuilder finding records that look like the example,
+// a struct or a map with string keys, by adding an AndEqual filter of each of its values.
+//
+// Struct fields are named by their json tags and fields tagged "-" or unexported are ignored, "omitempty" makes no difference.
+// Zero values are ignored, so a pointer to a zero value is needed to find it, e.g. &zero.
+// Nested structs and maps are flattened into dotted paths and embedded structs are promoted like encoding/json.
+// time.Time is a value, and values jsonbox can not compare, e.g. slices, fail the builder.
+func QueryFromExample(example interface{}) QueryBuilder {
+	builder := NewQueryBuilder().(*DefaultQueryBuilder)
+	value := reflect.ValueOf(example)
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return builder.fail(fmt.Errorf("%w: nil example", ErrInvalidQuery))
+		}
+		value = value.Elem()
+	}
+	if !isExampleObject(value) {
+		return builder.fail(fmt.Errorf("%w: example must be a struct or a map with string keys, got %T", ErrInvalidQuery, example))
+	}
+	addExample(builder, nil, value)
+	return builder
+}
+
+// Add the filters of the struct or map under the keys
+func addExample(builder *DefaultQueryBuilder, keys []string, value reflect.Value) {
+	if value.Kind() == reflect.Map {
+		names := make([]string, 0, value.Len())
+		for _, key := range value.MapKeys() {
+			names = append(names, key.String())
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			element := value.MapIndex(reflect.ValueOf(name).Convert(value.Type().Key()))
+			addExampleValue(builder, append(keys, name), element, false)
+		}
+		return
+	}
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		name, ok := jsonFieldName(field)
+		if !ok {
+			continue
+		}
+		fieldValue := value.Field(i)
+		if field.Anonymous && name == "" {
+			for fieldValue.Kind() == reflect.Ptr && !fieldValue.IsNil() {
+				fieldValue = fieldValue.Elem()
+			}
+			if fieldValue.Kind() == reflect.Struct {
+				addExample(builder, keys, fieldValue)
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+		addExampleValue(builder, append(keys, name), fieldValue, true)
+	}
+}
+
+// Add the filter of the value, zero values of struct fields are ignored
+func addExampleValue(builder *DefaultQueryBuilder, keys []string, value reflect.Value, skipZero bool) {
+	if skipZero && value.IsZero() {
+		return
+	}
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return
+		}
+		value = value.Elem()
+	}
+	field := Path(keys...)
+	switch {
+	case value.Type() == timeType:
+		builder.AndWhere(field, OpEqual, value.Interface())
+	case value.Type() == numberType:
+		builder.AndWhere(field, OpEqual, json.Number(value.String()))
+	case isExampleObject(value):
+		addExample(builder, append([]string{}, keys...), value)
+	case value.Kind() == reflect.String:
+		builder.AndWhere(field, OpEqual, value.String())
+	case value.Kind() == reflect.Bool:
+		builder.AndWhere(field, OpEqual, value.Bool())
+	case value.CanInt():
+		builder.AndWhere(field, OpEqual, value.Int())
+	case value.CanUint():
+		builder.AndWhere(field, OpEqual, value.Uint())
+	case value.CanFloat():
+		builder.AndWhere(field, OpEqual, value.Float())
+	default:
+		builder.fail(fmt.Errorf("%w: %s value of %q can not be compared", ErrInvalidQuery, value.Type(), field))
+	}
+}
+
+// Whether the value is a struct or a map with string keys
+func isExampleObject(value reflect.Value) bool {
+	return (value.Kind() == reflect.Struct && value.Type() != timeType) ||
+		(value.Kind() == reflect.Map && value.Type().Key().Kind() == reflect.String)
+}
+
+// The name of the field in the json tag, false when the field is not encoded
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	if !field.IsExported() {
+		return "", false
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	return name, true
+}
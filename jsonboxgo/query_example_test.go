@@ -0,0 +1,100 @@
+package jsonboxgo
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+type exampleAddress struct {
+	City string `json:"city,omitempty"`
+	Zip  string `json:"zip"`
+}
+
+type exampleUser struct {
+	Meta
+	Name     string            `json:"name"`
+	Age      int               `json:"age,omitempty"`
+	Score    *float64          `json:"score,omitempty"`
+	Active   bool              `json:"active"`
+	Address  exampleAddress    `json:"address"`
+	Birthday time.Time         `json:"birthday"`
+	Labels   map[string]string `json:"labels"`
+	Password string            `json:"-"`
+	Nickname string
+	internal string
+}
+
+func TestQueryFromExample(t *testing.T) {
+	zero := 0.0
+	// test cases
+	testCases := map[string]struct {
+		InputExample  interface{}
+		ExpectedQuery string
+		ExpectedError error
+	}{
+		"Zero values are ignored.": {
+			InputExample:  exampleUser{},
+			ExpectedQuery: "?",
+		},
+		"Struct.": {
+			InputExample:  exampleUser{Name: "taro", Age: 40, Active: true, Password: "secret", Nickname: "t", internal: "x"},
+			ExpectedQuery: "?q=name:=taro,age:=40,active:=true,Nickname:=t",
+		},
+		"Pointer to zero value.": {
+			InputExample:  &exampleUser{Score: &zero},
+			ExpectedQuery: "?q=score:=0",
+		},
+		"Nested struct and map.": {
+			InputExample:  exampleUser{Address: exampleAddress{City: "Tokyo"}, Labels: map[string]string{"team": "a", "example.com": "b"}},
+			ExpectedQuery: "?q=address.city:=Tokyo,labels.team:=a",
+			ExpectedError: ErrInvalidQuery,
+		},
+		"Embedded struct is promoted.": {
+			InputExample:  exampleUser{Meta: Meta{Id: "id001"}, Birthday: time.Date(2000, 1, 2, 3, 4, 5, 0, time.UTC)},
+			ExpectedQuery: "?q=_id:=id001,birthday:=2000-01-02T03:04:05.000Z",
+		},
+		"Map.": {
+			InputExample: map[string]interface{}{
+				"name":   "taro",
+				"age":    0,
+				"score":  json.Number("99.5"),
+				"user":   map[string]interface{}{"country": "JP", "tags": nil},
+				"active": false,
+			},
+			ExpectedQuery: "?q=active:=false,age:=0,name:=taro,score:=99.5,user.country:=JP",
+		},
+		"Slice is rejected.": {
+			InputExample:  map[string]interface{}{"tags": []string{"a"}},
+			ExpectedQuery: "?",
+			ExpectedError: ErrInvalidQuery,
+		},
+		"Not an object.": {
+			InputExample:  "name",
+			ExpectedQuery: "?",
+			ExpectedError: ErrInvalidQuery,
+		},
+		"Nil.": {
+			InputExample:  (*exampleUser)(nil),
+			ExpectedQuery: "?",
+			ExpectedError: ErrInvalidQuery,
+		},
+	}
+
+	// run
+	for testCase, param := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			query := QueryFromExample(param.InputExample)
+			actual := query.Build()
+			expected := param.ExpectedQuery
+			if actual != expected {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+			}
+			err := query.Err()
+			if !errors.Is(err, param.ExpectedError) || (err == nil) != (param.ExpectedError == nil) {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", err, err, param.ExpectedError, param.ExpectedError)
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package jsonboxgo
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// QueryFromMongoFilter translates a MongoDB filter document such as
+//
+//	map[string]interface{}{"age": map[string]interface{}{"$gte": 40}, "country": "JP"}
+//
+// into a HybridQuery. $eq, $gt, $gte, $lt and $lte are sent to jsonbox when it can express them,
+// and $in, $nin, $ne, $regex (with $options), $not, $and and $or are applied client-side.
+// Equality compares by type like MongoDB, while the range operators follow jsonbox, e.g. "40" is compared as a number.
+// Other operators are rejected with ErrUnsupportedQuery.
+func QueryFromMongoFilter(filter map[string]interface{}) (*HybridQuery, error) {
+	predicates, err := mongoPredicates(filter)
+	if err != nil {
+		return nil, err
+	}
+	query := NewHybridQuery(nil).Where(predicates...)
+	if err := query.Err(); err != nil {
+		return nil, err
+	}
+	return query, nil
+}
+
+// Translate the filter document, the predicates are ANDed
+func mongoPredicates(filter map[string]interface{}) ([]Predicate, error) {
+	predicates := make([]Predicate, 0)
+	for _, key := range sortedKeys(filter) {
+		value := filter[key]
+		if strings.HasPrefix(key, "$") {
+			predicate, err := mongoLogical(key, value)
+			if err != nil {
+				return nil, err
+			}
+			predicates = append(predicates, predicate)
+			continue
+		}
+		operators, isDocument := mongoDocument(value)
+		if _, isArray := mongoArray(value); isArray {
+			return nil, fmt.Errorf("%w: equality with an array of %q", ErrUnsupportedQuery, key)
+		} else if isDocument && !isOperatorDocument(operators) {
+			return nil, fmt.Errorf("%w: equality with a document of %q", ErrUnsupportedQuery, key)
+		} else if !isDocument {
+			operators = map[string]interface{}{"$eq": value}
+		}
+		fieldPredicates, err := mongoOperators(key, operators)
+		if err != nil {
+			return nil, err
+		}
+		predicates = append(predicates, fieldPredicates...)
+	}
+	return predicates, nil
+}
+
+// Translate $and and $or of filter documents
+func mongoLogical(operator string, value interface{}) (Predicate, error) {
+	if operator != "$and" && operator != "$or" {
+		return Predicate{}, fmt.Errorf("%w: top level operator %q", ErrUnsupportedQuery, operator)
+	}
+	elements, ok := mongoArray(value)
+	if !ok || len(elements) == 0 {
+		return Predicate{}, fmt.Errorf("%w: %s needs a non-empty array of filter documents", ErrInvalidQuery, operator)
+	}
+	branches := make([]Predicate, 0, len(elements))
+	for _, element := range elements {
+		document, ok := mongoDocument(element)
+		if !ok {
+			return Predicate{}, fmt.Errorf("%w: %s needs a non-empty array of filter documents", ErrInvalidQuery, operator)
+		}
+		predicates, err := mongoPredicates(document)
+		if err != nil {
+			return Predicate{}, err
+		}
+		branches = append(branches, And(predicates...))
+	}
+	if operator == "$or" {
+		return Or(branches...), nil
+	}
+	return And(branches...), nil
+}
+
+// Translate the operator document of the field
+func mongoOperators(field string, operators map[string]interface{}) ([]Predicate, error) {
+	predicates := make([]Predicate, 0, len(operators))
+	for _, operator := range sortedKeys(operators) {
+		if operator == "$options" {
+			if _, ok := operators["$regex"]; !ok {
+				return nil, fmt.Errorf("%w: $options of %q needs $regex", ErrInvalidQuery, field)
+			}
+			continue
+		}
+		predicate, err := mongoOperator(field, operator, operators[operator], operators["$options"])
+		if err != nil {
+			return nil, err
+		}
+		if err := predicate.Err(); err != nil {
+			return nil, err
+		}
+		predicates = append(predicates, predicate)
+	}
+	return predicates, nil
+}
+
+func mongoOperator(field string, operator string, value interface{}, options interface{}) (Predicate, error) {
+	switch operator {
+	case "$eq":
+		return In(field, value), nil
+	case "$ne":
+		return NotEqual(field, value), nil
+	case "$gt":
+		return Compare(field, OpGreaterThan, value), nil
+	case "$gte":
+		return Compare(field, OpGreaterThanOrEqual, value), nil
+	case "$lt":
+		return Compare(field, OpLessThan, value), nil
+	case "$lte":
+		return Compare(field, OpLessThanOrEqual, value), nil
+	case "$in", "$nin":
+		values, ok := mongoArray(value)
+		if !ok {
+			return Predicate{}, fmt.Errorf("%w: %s of %q needs an array", ErrInvalidQuery, operator, field)
+		}
+		if operator == "$nin" {
+			return NotIn(field, values...), nil
+		}
+		return In(field, values...), nil
+	case "$regex":
+		return mongoRegex(field, value, options)
+	case "$not":
+		operators, ok := mongoDocument(value)
+		if !ok || !isOperatorDocument(operators) {
+			if _, isRegex := value.(*regexp.Regexp); isRegex {
+				operators = map[string]interface{}{"$regex": value}
+			} else {
+				return Predicate{}, fmt.Errorf("%w: $not of %q needs an operator document", ErrInvalidQuery, field)
+			}
+		}
+		predicates, err := mongoOperators(field, operators)
+		if err != nil {
+			return Predicate{}, err
+		}
+		return Not(And(predicates...)), nil
+	}
+	return Predicate{}, fmt.Errorf("%w: operator %q of %q", ErrUnsupportedQuery, operator, field)
+}
+
+// Translate $regex, the pattern is a string or *regexp.Regexp and $options supports "i", "m" and "s"
+func mongoRegex(field string, value interface{}, options interface{}) (Predicate, error) {
+	var expression string
+	switch v := value.(type) {
+	case string:
+		expression = v
+	case *regexp.Regexp:
+		expression = v.String()
+	default:
+		return Predicate{}, fmt.Errorf("%w: $regex of %q needs a string, got %T", ErrInvalidQuery, field, value)
+	}
+	if options == nil {
+		return Regex(field, expression), nil
+	}
+	flags, ok := options.(string)
+	if !ok || strings.Trim(flags, "ims") != "" {
+		return Predicate{}, fmt.Errorf("%w: $options %v of %q, only \"i\", \"m\" and \"s\" are supported", ErrUnsupportedQuery, options, field)
+	}
+	if flags != "" {
+		expression = "(?" + flags + ")" + expression
+	}
+	return Regex(field, expression), nil
+}
+
+// Whether every key of the document is an operator
+func isOperatorDocument(document map[string]interface{}) bool {
+	if len(document) == 0 {
+		return false
+	}
+	for key := range document {
+		if !strings.HasPrefix(key, "$") {
+			return false
+		}
+	}
+	return true
+}
+
+// The value as a document, maps with string keys such as bson.M are accepted
+func mongoDocument(value interface{}) (map[string]interface{}, bool) {
+	if document, ok := value.(map[string]interface{}); ok {
+		return document, true
+	}
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Map || v.Type().Key().Kind() != reflect.String {
+		return nil, false
+	}
+	document := make(map[string]interface{}, v.Len())
+	for _, key := range v.MapKeys() {
+		document[key.String()] = v.MapIndex(key).Interface()
+	}
+	return document, true
+}
+
+// The value as an array, slices of any type are accepted
+func mongoArray(value interface{}) ([]interface{}, bool) {
+	if array, ok := value.([]interface{}); ok {
+		return array, true
+	}
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, false
+	}
+	array := make([]interface{}, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		array = append(array, v.Index(i).Interface())
+	}
+	return array, true
+}
+
+func sortedKeys(document map[string]interface{}) []string {
+	keys := make([]string, 0, len(document))
+	for key := range document {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
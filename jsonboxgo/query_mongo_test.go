@@ -0,0 +1,146 @@
+package jsonboxgo
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+type testMongoDocument map[string]interface{}
+
+func TestQueryFromMongoFilter(t *testing.T) {
+	records := []map[string]interface{}{
+		{"_id": "1", "name": "Taro", "age": 40.0, "country": "JP", "tags": []interface{}{"a"}},
+		{"_id": "2", "name": "jiro", "age": 20.0, "country": "US", "status": "archived"},
+		{"_id": "3", "name": "Saburo", "age": "40", "country": "FR"},
+	}
+	// test cases
+	testCases := map[string]struct {
+		InputFilter         map[string]interface{}
+		ExpectedServerQuery string
+		ExpectedIds         []string
+		ExpectedError       error
+	}{
+		"Empty.": {
+			InputFilter:         map[string]interface{}{},
+			ExpectedServerQuery: "?",
+			ExpectedIds:         []string{"1", "2", "3"},
+		},
+		"Implicit $eq and range.": {
+			InputFilter:         map[string]interface{}{"age": map[string]interface{}{"$gte": 40}, "country": "JP"},
+			ExpectedServerQuery: "?q=age:>=40,country:=JP",
+			ExpectedIds:         []string{"1"},
+		},
+		"All range operators.": {
+			InputFilter:         map[string]interface{}{"age": map[string]interface{}{"$gt": 10, "$lt": 50, "$lte": 40.0, "$eq": 20}},
+			ExpectedServerQuery: "?q=age:=20,age:>10,age:<50,age:<=40",
+			ExpectedIds:         []string{"2"},
+		},
+		"Typed $eq is client-side.": {
+			InputFilter:         map[string]interface{}{"age": "40"},
+			ExpectedServerQuery: "?",
+			ExpectedIds:         []string{"3"},
+		},
+		"$in and $nin.": {
+			InputFilter:         map[string]interface{}{"country": map[string]interface{}{"$in": []string{"JP", "US"}}, "tags": map[string]interface{}{"$nin": []interface{}{"a"}}},
+			ExpectedServerQuery: "?",
+			ExpectedIds:         []string{"2"},
+		},
+		"$ne.": {
+			InputFilter:         map[string]interface{}{"status": map[string]interface{}{"$ne": "archived"}},
+			ExpectedServerQuery: "?",
+			ExpectedIds:         []string{"1", "3"},
+		},
+		"$regex with $options.": {
+			InputFilter:         map[string]interface{}{"name": map[string]interface{}{"$regex": "^[st]", "$options": "i"}},
+			ExpectedServerQuery: "?",
+			ExpectedIds:         []string{"1", "3"},
+		},
+		"$regex of regexp.": {
+			InputFilter:         map[string]interface{}{"name": map[string]interface{}{"$regex": regexp.MustCompile("ro$")}},
+			ExpectedServerQuery: "?",
+			ExpectedIds:         []string{"1", "2", "3"},
+		},
+		"$not.": {
+			InputFilter:         map[string]interface{}{"name": map[string]interface{}{"$not": map[string]interface{}{"$regex": "^T"}}},
+			ExpectedServerQuery: "?",
+			ExpectedIds:         []string{"2", "3"},
+		},
+		"$or and $and of named document type.": {
+			InputFilter: map[string]interface{}{
+				"$or": []testMongoDocument{
+					{"country": "FR"},
+					{"$and": []interface{}{testMongoDocument{"age": testMongoDocument{"$lt": 30}}, map[string]interface{}{"name": "jiro"}}},
+				},
+			},
+			ExpectedServerQuery: "?",
+			ExpectedIds:         []string{"2", "3"},
+		},
+		"Nested field.": {
+			InputFilter:         map[string]interface{}{"user.country": map[string]interface{}{"$eq": "JP"}},
+			ExpectedServerQuery: "?q=user.country:=JP",
+			ExpectedIds:         []string{},
+		},
+		"Unsupported operator.": {
+			InputFilter:   map[string]interface{}{"tags": map[string]interface{}{"$size": 1}},
+			ExpectedError: ErrUnsupportedQuery,
+		},
+		"Unsupported top level operator.": {
+			InputFilter:   map[string]interface{}{"$where": "this.age > 1"},
+			ExpectedError: ErrUnsupportedQuery,
+		},
+		"Unsupported regex option.": {
+			InputFilter:   map[string]interface{}{"name": map[string]interface{}{"$regex": "a", "$options": "x"}},
+			ExpectedError: ErrUnsupportedQuery,
+		},
+		"Equality with a document.": {
+			InputFilter:   map[string]interface{}{"user": map[string]interface{}{"country": "JP"}},
+			ExpectedError: ErrUnsupportedQuery,
+		},
+		"Equality with an array.": {
+			InputFilter:   map[string]interface{}{"tags": []string{"a"}},
+			ExpectedError: ErrUnsupportedQuery,
+		},
+		"$in without array.": {
+			InputFilter:   map[string]interface{}{"country": map[string]interface{}{"$in": "JP"}},
+			ExpectedError: ErrInvalidQuery,
+		},
+		"Invalid regex.": {
+			InputFilter:   map[string]interface{}{"name": map[string]interface{}{"$regex": "("}},
+			ExpectedError: ErrInvalidQuery,
+		},
+		"$or without documents.": {
+			InputFilter:   map[string]interface{}{"$or": []interface{}{}},
+			ExpectedError: ErrInvalidQuery,
+		},
+	}
+
+	// run
+	for testCase, param := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			query, err := QueryFromMongoFilter(param.InputFilter)
+			if !errors.Is(err, param.ExpectedError) || (err == nil) != (param.ExpectedError == nil) {
+				t.Fatalf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", err, err, param.ExpectedError, param.ExpectedError)
+			}
+			if err != nil {
+				return
+			}
+			actual := query.ServerQuery().Build()
+			expected := param.ExpectedServerQuery
+			if actual != expected {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+			}
+			actualIds := make([]string, 0)
+			for _, record := range records {
+				if query.Match(record) {
+					actualIds = append(actualIds, record["_id"].(string))
+				}
+			}
+			expectedIds := param.ExpectedIds
+			if !reflect.DeepEqual(actualIds, expectedIds) {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actualIds, actualIds, expectedIds, expectedIds)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package jsonboxgo
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ParseQuery parses a query string built by QueryBuilder.Build back into a QueryBuilder.
+// The leading "?" is optional. ParseQuery(q.Build()).Build() equals q.Build().
+func ParseQuery(query string) (QueryBuilder, error) {
+	builder, err := parseQueryString(query)
+	if err != nil {
+		return nil, err
+	}
+	return builder, nil
+}
+
+// Parse the query string into DefaultQueryBuilder
+func parseQueryString(query string) (*DefaultQueryBuilder, error) {
+	builder := NewQueryBuilder().(*DefaultQueryBuilder)
+	query = strings.TrimPrefix(query, "?")
+	if query == "" {
+		return builder, nil
+	}
+	for _, param := range strings.Split(query, "&") {
+		name, value, _ := strings.Cut(param, "=")
+		switch name {
+		case "offset", "limit":
+			number, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid %s %q", ErrInvalidQuery, name, value)
+			}
+			if name == "offset" {
+				builder.Offset(number)
+			} else {
+				builder.Limit(number)
+			}
+		case "sort":
+			sort, err := url.QueryUnescape(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid sort %q: %v", ErrInvalidQuery, value, err)
+			}
+			if strings.HasPrefix(sort, "-") {
+				builder.SortDesc(fieldFromServer(sort[1:]))
+			} else {
+				builder.SortAsc(fieldFromServer(sort))
+			}
+		case "q":
+			if err := parseFilters(builder, value); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("%w: unknown parameter %q", ErrInvalidQuery, name)
+		}
+		if err := builder.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return builder, nil
+}
+
+// Parse the "q" parameter, e.g. "country:=JP,age:>=40,name:taro*"
+func parseFilters(builder *DefaultQueryBuilder, q string) error {
+	if q == "" {
+		return nil
+	}
+	for _, term := range strings.Split(q, ",") {
+		f, err := parseFilter(term)
+		if err != nil {
+			return err
+		}
+		builder.AndWhere(f.field, Operator(f.operator), f.value)
+	}
+	return nil
+}
+
+// Parse a filter, the field ends at the first ":" and the operator follows it
+func parseFilter(term string) (filter, error) {
+	rawField, rawValue, found := strings.Cut(term, ":")
+	if !found {
+		return filter{}, fmt.Errorf("%w: filter %q has no operator", ErrInvalidQuery, term)
+	}
+	operator := OpMatch
+	for _, candidate := range []Operator{OpGreaterThanOrEqual, OpLessThanOrEqual, OpGreaterThan, OpLessThan, OpEqual} {
+		if strings.HasPrefix(rawValue, string(candidate[1:])) {
+			operator = candidate
+			rawValue = rawValue[len(candidate)-1:]
+			break
+		}
+	}
+	field, err := url.QueryUnescape(rawField)
+	if err != nil {
+		return filter{}, fmt.Errorf("%w: invalid field %q: %v", ErrInvalidQuery, rawField, err)
+	}
+	value, err := url.QueryUnescape(rawValue)
+	if err != nil {
+		return filter{}, fmt.Errorf("%w: invalid value %q: %v", ErrInvalidQuery, rawValue, err)
+	}
+	return filter{field: fieldFromServer(field), operator: string(operator), value: value}, nil
+}
+
+// Value of the parameter, "" when not set
+func (d *DefaultQueryBuilder) param(name string) string {
+	for _, query := range d.queries {
+		if query.name == name {
+			return query.value
+		}
+	}
+	return ""
+}
+
+// The "q" parameter, "" when no filter is set
+func (d *DefaultQueryBuilder) filterParam() string {
+	if len(d.filters) == 0 {
+		return ""
+	}
+	filters := make([]string, 0, len(d.filters))
+	for _, f := range d.filters {
+		filters = append(filters, f.String())
+	}
+	return "q=" + strings.Join(filters, ",")
+}
@@ -0,0 +1,158 @@
+package jsonboxgo
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestParseQuery(t *testing.T) {
+	// test cases
+	testCases := map[string]struct {
+		InputQuery    string
+		ExpectedQuery string
+		ExpectedError error
+	}{
+		"Empty.": {
+			InputQuery:    "",
+			ExpectedQuery: "?",
+		},
+		"Question mark only.": {
+			InputQuery:    "?",
+			ExpectedQuery: "?",
+		},
+		"All parameters.": {
+			InputQuery:    "?offset=1&limit=3&sort=age&q=age:>=40",
+			ExpectedQuery: "?offset=1&limit=3&sort=age&q=age:>=40",
+		},
+		"Without question mark.": {
+			InputQuery:    "limit=3&sort=-name",
+			ExpectedQuery: "?limit=3&sort=-name",
+		},
+		"Parameter order is kept.": {
+			InputQuery:    "?q=name:taro*&sort=-age&limit=5",
+			ExpectedQuery: "?sort=-age&limit=5&q=name:taro*",
+		},
+		"All operators.": {
+			InputQuery:    "?q=country:=JP,age:>20,age:>=21,age:<60,age:<=59,name:*ro",
+			ExpectedQuery: "?q=country:=JP,age:>20,age:>=21,age:<60,age:<=59,name:*ro",
+		},
+		"Escaped value.": {
+			InputQuery:    "?q=name:=Taro%20Yamada%26Co,memo:50%25%20off*",
+			ExpectedQuery: "?q=name:=Taro%20Yamada%26Co,memo:50%25%20off*",
+		},
+		"Escaped asterisk.": {
+			InputQuery:    "?q=memo:%5B*%5Dsale%5B*%5D*",
+			ExpectedQuery: "?q=memo:%5B*%5Dsale%5B*%5D*",
+		},
+		"Time value.": {
+			InputQuery:    "?q=_createdOn:>2020-01-02T03:04:05.000Z",
+			ExpectedQuery: "?q=_createdOn:>2020-01-02T03:04:05.000Z",
+		},
+		"Invalid offset.": {
+			InputQuery:    "?offset=one",
+			ExpectedError: ErrInvalidQuery,
+		},
+		"Unknown parameter.": {
+			InputQuery:    "?page=1",
+			ExpectedError: ErrInvalidQuery,
+		},
+		"Filter without operator.": {
+			InputQuery:    "?q=country",
+			ExpectedError: ErrInvalidQuery,
+		},
+		"Empty field.": {
+			InputQuery:    "?q=:=JP",
+			ExpectedError: ErrInvalidQuery,
+		},
+		"Broken escape.": {
+			InputQuery:    "?q=name:=%zz",
+			ExpectedError: ErrInvalidQuery,
+		},
+		"Invalid pattern.": {
+			InputQuery:    "?q=name:ta(ro",
+			ExpectedError: ErrInvalidQuery,
+		},
+	}
+
+	// run
+	for testCase, param := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			builder, err := ParseQuery(param.InputQuery)
+			if !errors.Is(err, param.ExpectedError) || (err == nil) != (param.ExpectedError == nil) {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", err, err, param.ExpectedError, param.ExpectedError)
+			}
+			if err != nil {
+				return
+			}
+			actual := builder.Build()
+			expected := param.ExpectedQuery
+			if actual != expected {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+			}
+		})
+	}
+}
+
+func TestParseQueryRoundTrip(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	fields := []string{"age", "name", "memo", "user.country", "_createdOn"}
+	texts := []string{"", "taro", "Taro Yamada", "50% off", "a&b=c", "*", "\\", "日本", "x:y", "<tag>", "-1"}
+	pick := func(values []string) string {
+		return values[random.Intn(len(values))]
+	}
+
+	for i := 0; i < 1000; i++ {
+		builder := NewQueryBuilder()
+		if random.Intn(2) == 0 {
+			builder.Offset(random.Intn(100))
+		}
+		if random.Intn(2) == 0 {
+			builder.Limit(random.Intn(100))
+		}
+		switch random.Intn(3) {
+		case 0:
+			builder.SortAsc(pick(fields))
+		case 1:
+			builder.SortDesc(pick(fields))
+		}
+		for n := random.Intn(4); n > 0; n-- {
+			field := pick(fields)
+			switch random.Intn(9) {
+			case 0:
+				builder.AndEqual(field, pick(texts))
+			case 1:
+				builder.AndWhere(field, OpEqual, random.Intn(2) == 0)
+			case 2:
+				builder.AndGreaterThanInt(field, random.Intn(200)-100)
+			case 3:
+				builder.AndWhere(field, OpLessThanOrEqual, float64(random.Intn(100))/4)
+			case 4:
+				builder.AndWhere(field, OpGreaterThanOrEqual, time.Unix(random.Int63n(2e9), 0))
+			case 5:
+				builder.AndStartsWith(field, pick(texts))
+			case 6:
+				builder.AndEndsWith(field, pick(texts))
+			case 7:
+				builder.AndContains(field, pick(texts))
+			case 8:
+				builder.AndLessThan(field, pick(texts))
+			}
+		}
+		if builder.Err() != nil {
+			continue
+		}
+
+		query := builder.Build()
+		parsed, err := ParseQuery(query)
+		if err != nil {
+			t.Fatalf("  Failed: query -> %v, error -> %v\n", query, err)
+		}
+		actual := parsed.Build()
+		expected := query
+		if actual != expected {
+			t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+		}
+	}
+}
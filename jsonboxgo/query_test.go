@@ -0,0 +1,141 @@
+package jsonboxgo
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestQueryBuilderBuild(t *testing.T) {
+	// test cases
+	testCases := map[string]struct {
+		InputQuery    QueryBuilder
+		ExpectedQuery string
+		ExpectedError error
+	}{
+		"Empty.": {
+			InputQuery:    NewQueryBuilder(),
+			ExpectedQuery: "?",
+		},
+		"Queries and filters.": {
+			InputQuery:    NewQueryBuilder().Offset(1).Limit(3).SortAsc("age").AndEqual("country", "JP").AndGreaterThanOrEqual("age", "40"),
+			ExpectedQuery: "?offset=1&limit=3&sort=age&q=country:=JP,age:>=40",
+		},
+		"Descending sort.": {
+			InputQuery:    NewQueryBuilder().SortDesc("_createdOn"),
+			ExpectedQuery: "?sort=-_createdOn",
+		},
+		"All operators.": {
+			InputQuery:    NewQueryBuilder().AndGreaterThan("a", "1").AndLessThan("b", "2").AndLessThanOrEqual("c", "3").AndEqual("d", "4"),
+			ExpectedQuery: "?q=a:>1,b:<2,c:<=3,d:=4",
+		},
+		"Spaces and ampersands are encoded.": {
+			InputQuery:    NewQueryBuilder().AndEqual("name", "taro & jiro"),
+			ExpectedQuery: "?q=name:=taro%20%26%20jiro",
+		},
+		"Non-ASCII is encoded.": {
+			InputQuery:    NewQueryBuilder().AndEqual("名前", "太郎"),
+			ExpectedQuery: "?q=%E5%90%8D%E5%89%8D:=%E5%A4%AA%E9%83%8E",
+		},
+		"Colon in value is kept.": {
+			InputQuery:    NewQueryBuilder().AndGreaterThan("_createdOn", "2020-04-26T16:26:13.935Z"),
+			ExpectedQuery: "?q=_createdOn:>2020-04-26T16:26:13.935Z",
+		},
+		"Comma in value.": {
+			InputQuery:    NewQueryBuilder().AndEqual("name", "taro,jiro").Limit(1),
+			ExpectedQuery: "?limit=1",
+			ExpectedError: ErrInvalidQuery,
+		},
+		"Colon in field.": {
+			InputQuery:    NewQueryBuilder().AndEqual("a:b", "taro"),
+			ExpectedQuery: "?",
+			ExpectedError: ErrInvalidQuery,
+		},
+		"Empty field.": {
+			InputQuery:    NewQueryBuilder().SortAsc(""),
+			ExpectedQuery: "?",
+			ExpectedError: ErrInvalidQuery,
+		},
+		"Empty value.": {
+			InputQuery:    NewQueryBuilder().AndEqual("name", ""),
+			ExpectedQuery: "?",
+			ExpectedError: ErrInvalidQuery,
+		},
+		"Negative limit.": {
+			InputQuery:    NewQueryBuilder().Limit(-1),
+			ExpectedQuery: "?",
+			ExpectedError: ErrInvalidQuery,
+		},
+	}
+
+	// run
+	for testCase, param := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			actual := param.InputQuery.Build()
+			expected := param.ExpectedQuery
+			if actual != expected {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+			}
+			err := param.InputQuery.Err()
+			if (param.ExpectedError == nil && err != nil) || !errors.Is(err, param.ExpectedError) {
+				t.Errorf("  Failed: err -> %v(%T), expected -> %v\n", err, err, param.ExpectedError)
+			}
+		})
+	}
+}
+
+func TestInvalidQueryIsNotSent(t *testing.T) {
+	requests := make([]*http.Request, 0)
+	client := NewTestJsonboxClient(CreateNewSequenceTestClient([]TestResponse{}, &requests))
+	_, err := client.ReadByQueryWithError("users", NewQueryBuilder().AndEqual("name", "a,b"))
+	if !errors.Is(err, ErrInvalidQuery) || len(requests) != 0 {
+		t.Errorf("  Failed: err -> %v(%T), requestCount -> %v\n", err, err, len(requests))
+	}
+}
+
+func TestInvalidQueryLegacy(t *testing.T) {
+	// test cases
+	testCases := map[string]struct {
+		InputQuery QueryBuilder
+	}{
+		"Comma in the value.": {
+			InputQuery: NewQueryBuilder().AndEqual("name", "a,b"),
+		},
+		"Empty value.": {
+			InputQuery: NewQueryBuilder().AndEqual("name", ""),
+		},
+		"Empty sort field.": {
+			InputQuery: NewQueryBuilder().SortAsc(""),
+		},
+	}
+
+	// run
+	for testCase, param := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			requests := make([]*http.Request, 0)
+			client := NewTestJsonboxClient(CreateNewSequenceTestClient([]TestResponse{}, &requests))
+			cachedClient, _ := NewCachedClient(client, CacheConfig{TTL: time.Minute})
+			for _, c := range []Client{client, cachedClient} {
+				actual := c.ReadByQuery("users", param.InputQuery)
+				if actual != nil {
+					t.Errorf("  Failed: actual -> %v(%T), expected -> nil\n", actual, actual)
+				}
+			}
+			if len(requests) != 0 {
+				t.Errorf("  Failed: requestCount -> %v, expected -> 0\n", len(requests))
+			}
+		})
+	}
+}
+
+func TestRequestPathIsEscaped(t *testing.T) {
+	requests := make([]*http.Request, 0)
+	client := NewTestJsonboxClient(CreateNewSequenceTestClient([]TestResponse{{StatusCode: 200, Body: `{}`}}, &requests))
+	_, _ = client.ReadWithError("/my users/", "id 001?#")
+	actual := requests[0].URL.String()
+	expected := "https://test.com/box_test/my%20users/id%20001%3F%23"
+	if actual != expected {
+		t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+	}
+}
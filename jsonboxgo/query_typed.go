@@ -0,0 +1,183 @@
+package jsonboxgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Operator is a comparison operator of jsonbox's query syntax.
+type Operator string
+
+const (
+	OpEqual              Operator = ":="
+	OpGreaterThan        Operator = ":>"
+	OpGreaterThanOrEqual Operator = ":>="
+	OpLessThan           Operator = ":<"
+	OpLessThanOrEqual    Operator = ":<="
+)
+
+// TimeFormat is the format of "_createdOn" and "_updatedOn", time.Time values are formatted with it in UTC.
+const TimeFormat = "2006-01-02T15:04:05.000Z"
+
+// AndWhere adds a filter with a typed value.
+// string, bool, integers, floats, json.Number and time.Time are accepted, bool only with OpEqual.
+// OpMatch accepts a string pattern only.
+func (d *DefaultQueryBuilder) AndWhere(field string, operator Operator, value interface{}) QueryBuilder {
+	f, err := newFilter(field, operator, value)
+	if err != nil {
+		return d.fail(err)
+	}
+	d.filters = append(d.filters, f)
+	return d
+}
+
+func (d *DefaultQueryBuilder) AndEqualInt(field string, value int) QueryBuilder {
+	return d.AndWhere(field, OpEqual, value)
+}
+
+func (d *DefaultQueryBuilder) AndEqualFloat(field string, value float64) QueryBuilder {
+	return d.AndWhere(field, OpEqual, value)
+}
+
+func (d *DefaultQueryBuilder) AndEqualBool(field string, value bool) QueryBuilder {
+	return d.AndWhere(field, OpEqual, value)
+}
+
+func (d *DefaultQueryBuilder) AndGreaterThanInt(field string, value int) QueryBuilder {
+	return d.AndWhere(field, OpGreaterThan, value)
+}
+
+func (d *DefaultQueryBuilder) AndGreaterThanOrEqualInt(field string, value int) QueryBuilder {
+	return d.AndWhere(field, OpGreaterThanOrEqual, value)
+}
+
+func (d *DefaultQueryBuilder) AndLessThanInt(field string, value int) QueryBuilder {
+	return d.AndWhere(field, OpLessThan, value)
+}
+
+func (d *DefaultQueryBuilder) AndLessThanOrEqualInt(field string, value int) QueryBuilder {
+	return d.AndWhere(field, OpLessThanOrEqual, value)
+}
+
+func (d *DefaultQueryBuilder) AndGreaterThanFloat(field string, value float64) QueryBuilder {
+	return d.AndWhere(field, OpGreaterThan, value)
+}
+
+func (d *DefaultQueryBuilder) AndGreaterThanOrEqualFloat(field string, value float64) QueryBuilder {
+	return d.AndWhere(field, OpGreaterThanOrEqual, value)
+}
+
+func (d *DefaultQueryBuilder) AndLessThanFloat(field string, value float64) QueryBuilder {
+	return d.AndWhere(field, OpLessThan, value)
+}
+
+func (d *DefaultQueryBuilder) AndLessThanOrEqualFloat(field string, value float64) QueryBuilder {
+	return d.AndWhere(field, OpLessThanOrEqual, value)
+}
+
+// AndCreatedAfter filters records created after the time (exclusive).
+func (d *DefaultQueryBuilder) AndCreatedAfter(t time.Time) QueryBuilder {
+	return d.AndWhere("_createdOn", OpGreaterThan, t)
+}
+
+// AndCreatedBefore filters records created before the time (exclusive).
+func (d *DefaultQueryBuilder) AndCreatedBefore(t time.Time) QueryBuilder {
+	return d.AndWhere("_createdOn", OpLessThan, t)
+}
+
+// AndUpdatedAfter filters records updated after the time (exclusive).
+func (d *DefaultQueryBuilder) AndUpdatedAfter(t time.Time) QueryBuilder {
+	return d.AndWhere("_updatedOn", OpGreaterThan, t)
+}
+
+// AndUpdatedBefore filters records updated before the time (exclusive).
+func (d *DefaultQueryBuilder) AndUpdatedBefore(t time.Time) QueryBuilder {
+	return d.AndWhere("_updatedOn", OpLessThan, t)
+}
+
+// Create the filter of a typed value
+func newFilter(field string, operator Operator, value interface{}) (filter, error) {
+	var formatted string
+	switch operator {
+	case OpMatch:
+		pattern, ok := value.(string)
+		if !ok {
+			return filter{}, fmt.Errorf("%w: operator %q can not be used with %T value of %q", ErrInvalidQuery, operator, value, field)
+		}
+		if err := validatePattern(field, pattern); err != nil {
+			return filter{}, err
+		}
+		formatted = pattern
+	case OpEqual, OpGreaterThan, OpGreaterThanOrEqual, OpLessThan, OpLessThanOrEqual:
+		var err error
+		if formatted, err = formatValue(field, operator, value); err != nil {
+			return filter{}, err
+		}
+	default:
+		return filter{}, fmt.Errorf("%w: unknown operator %q", ErrInvalidQuery, operator)
+	}
+	if err := validateFilter(field, string(operator), formatted); err != nil {
+		return filter{}, err
+	}
+	return filter{field: field, operator: string(operator), value: formatted}, nil
+}
+
+// Serialize the typed value in the form jsonbox compares it
+func formatValue(field string, operator Operator, value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case bool:
+		if operator != OpEqual {
+			return "", fmt.Errorf("%w: operator %q can not be used with bool value of %q", ErrInvalidQuery, operator, field)
+		}
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int8:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int16:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int32:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint8:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint16:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float32:
+		return formatFloat(field, float64(v), 32)
+	case float64:
+		return formatFloat(field, v, 64)
+	case json.Number:
+		if _, err := v.Float64(); err != nil {
+			return "", fmt.Errorf("%w: invalid number %q of %q", ErrInvalidQuery, v, field)
+		}
+		return v.String(), nil
+	case time.Time:
+		if v.IsZero() {
+			return "", fmt.Errorf("%w: zero time of %q", ErrInvalidQuery, field)
+		}
+		return v.UTC().Format(TimeFormat), nil
+	case nil:
+		return "", fmt.Errorf("%w: nil value of %q", ErrInvalidQuery, field)
+	}
+	return "", fmt.Errorf("%w: unsupported value type %T of %q", ErrInvalidQuery, value, field)
+}
+
+func formatFloat(field string, value float64, bitSize int) (string, error) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return "", fmt.Errorf("%w: %v of %q can not be compared", ErrInvalidQuery, value, field)
+	}
+	return strconv.FormatFloat(value, 'f', -1, bitSize), nil
+}
@@ -0,0 +1,81 @@
+package jsonboxgo
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestQueryBuilderTypedValues(t *testing.T) {
+	// test cases
+	createdOn := time.Date(2020, 4, 26, 16, 26, 13, 935000000, time.UTC)
+	jst := time.FixedZone("JST", 9*60*60)
+	testCases := map[string]struct {
+		InputQuery    QueryBuilder
+		ExpectedQuery string
+		ExpectedError error
+	}{
+		"Int.": {
+			InputQuery:    NewQueryBuilder().AndEqualInt("age", 40).AndGreaterThanInt("a", -1).AndGreaterThanOrEqualInt("b", 2).AndLessThanInt("c", 3).AndLessThanOrEqualInt("d", 4),
+			ExpectedQuery: "?q=age:=40,a:>-1,b:>=2,c:<3,d:<=4",
+		},
+		"Float.": {
+			InputQuery:    NewQueryBuilder().AndEqualFloat("score", 1.5).AndGreaterThanFloat("a", 1e21).AndGreaterThanOrEqualFloat("b", 0.1).AndLessThanFloat("c", -2).AndLessThanOrEqualFloat("d", 3.25),
+			ExpectedQuery: "?q=score:=1.5,a:>1000000000000000000000,b:>=0.1,c:<-2,d:<=3.25",
+		},
+		"Bool.": {
+			InputQuery:    NewQueryBuilder().AndEqualBool("isDead", false),
+			ExpectedQuery: "?q=isDead:=false",
+		},
+		"Created and updated.": {
+			InputQuery:    NewQueryBuilder().AndCreatedAfter(createdOn).AndCreatedBefore(createdOn.In(jst)).AndUpdatedAfter(createdOn).AndUpdatedBefore(createdOn),
+			ExpectedQuery: "?q=_createdOn:>2020-04-26T16:26:13.935Z,_createdOn:<2020-04-26T16:26:13.935Z,_updatedOn:>2020-04-26T16:26:13.935Z,_updatedOn:<2020-04-26T16:26:13.935Z",
+		},
+		"Where with other types.": {
+			InputQuery:    NewQueryBuilder().AndWhere("a", OpEqual, uint8(1)).AndWhere("b", OpLessThan, json.Number("2.5")).AndWhere("c", OpEqual, "text"),
+			ExpectedQuery: "?q=a:=1,b:<2.5,c:=text",
+		},
+		"Range operator on bool.": {
+			InputQuery:    NewQueryBuilder().AndWhere("isDead", OpGreaterThan, true),
+			ExpectedQuery: "?",
+			ExpectedError: ErrInvalidQuery,
+		},
+		"NaN.": {
+			InputQuery:    NewQueryBuilder().AndEqualFloat("score", math.NaN()),
+			ExpectedQuery: "?",
+			ExpectedError: ErrInvalidQuery,
+		},
+		"Zero time.": {
+			InputQuery:    NewQueryBuilder().AndCreatedAfter(time.Time{}),
+			ExpectedQuery: "?",
+			ExpectedError: ErrInvalidQuery,
+		},
+		"Unsupported type.": {
+			InputQuery:    NewQueryBuilder().AndWhere("tags", OpEqual, []string{"a"}),
+			ExpectedQuery: "?",
+			ExpectedError: ErrInvalidQuery,
+		},
+		"Unknown operator.": {
+			InputQuery:    NewQueryBuilder().AndWhere("age", Operator(":!"), 1),
+			ExpectedQuery: "?",
+			ExpectedError: ErrInvalidQuery,
+		},
+	}
+
+	// run
+	for testCase, param := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			actual := param.InputQuery.Build()
+			expected := param.ExpectedQuery
+			if actual != expected {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+			}
+			err := param.InputQuery.Err()
+			if (param.ExpectedError == nil && err != nil) || !errors.Is(err, param.ExpectedError) {
+				t.Errorf("  Failed: err -> %v(%T), expected -> %v\n", err, err, param.ExpectedError)
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package jsonboxgo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Query is an immutable query, every method returns a new Query and leaves the receiver unchanged.
+// It is safe to build once and reuse across requests and goroutines.
+// Query is comparable, so it can be used as a map key. Offset, limit and sort are last-wins.
+//
+//	base := jsonboxgo.NewQuery().AndEqual("country", "JP")
+//	page := base.SortDesc("age").Limit(10)
+type Query struct {
+	offset  string
+	limit   string
+	sort    string
+	filters string
+	err     error
+}
+
+// Create new empty Query
+func NewQuery() Query {
+	return Query{}
+}
+
+func (q Query) Offset(offset int) Query {
+	if offset < 0 {
+		return q.fail(fmt.Errorf("%w: negative offset %d", ErrInvalidQuery, offset))
+	}
+	q.offset = strconv.Itoa(offset)
+	return q
+}
+
+func (q Query) Limit(limit int) Query {
+	if limit < 0 {
+		return q.fail(fmt.Errorf("%w: negative limit %d", ErrInvalidQuery, limit))
+	}
+	q.limit = strconv.Itoa(limit)
+	return q
+}
+
+func (q Query) SortAsc(sort string) Query {
+	if err := validateField(sort); err != nil {
+		return q.fail(err)
+	}
+	q.sort = sort
+	return q
+}
+
+func (q Query) SortDesc(sort string) Query {
+	if err := validateField(sort); err != nil {
+		return q.fail(err)
+	}
+	q.sort = "-" + sort
+	return q
+}
+
+func (q Query) AndEqual(field string, value string) Query {
+	return q.AndWhere(field, OpEqual, value)
+}
+
+func (q Query) AndGreaterThan(field string, value string) Query {
+	return q.AndWhere(field, OpGreaterThan, value)
+}
+
+func (q Query) AndGreaterThanOrEqual(field string, value string) Query {
+	return q.AndWhere(field, OpGreaterThanOrEqual, value)
+}
+
+func (q Query) AndLessThan(field string, value string) Query {
+	return q.AndWhere(field, OpLessThan, value)
+}
+
+func (q Query) AndLessThanOrEqual(field string, value string) Query {
+	return q.AndWhere(field, OpLessThanOrEqual, value)
+}
+
+// AndWhere adds a filter with a typed value, same as DefaultQueryBuilder.AndWhere.
+func (q Query) AndWhere(field string, operator Operator, value interface{}) Query {
+	f, err := newFilter(field, operator, value)
+	if err != nil {
+		return q.fail(err)
+	}
+	if q.filters != "" {
+		q.filters += ","
+	}
+	q.filters += f.String()
+	return q
+}
+
+func (q Query) AndEqualInt(field string, value int) Query {
+	return q.AndWhere(field, OpEqual, value)
+}
+
+func (q Query) AndEqualFloat(field string, value float64) Query {
+	return q.AndWhere(field, OpEqual, value)
+}
+
+func (q Query) AndEqualBool(field string, value bool) Query {
+	return q.AndWhere(field, OpEqual, value)
+}
+
+func (q Query) AndGreaterThanInt(field string, value int) Query {
+	return q.AndWhere(field, OpGreaterThan, value)
+}
+
+func (q Query) AndGreaterThanOrEqualInt(field string, value int) Query {
+	return q.AndWhere(field, OpGreaterThanOrEqual, value)
+}
+
+func (q Query) AndLessThanInt(field string, value int) Query {
+	return q.AndWhere(field, OpLessThan, value)
+}
+
+func (q Query) AndLessThanOrEqualInt(field string, value int) Query {
+	return q.AndWhere(field, OpLessThanOrEqual, value)
+}
+
+func (q Query) AndGreaterThanFloat(field string, value float64) Query {
+	return q.AndWhere(field, OpGreaterThan, value)
+}
+
+func (q Query) AndGreaterThanOrEqualFloat(field string, value float64) Query {
+	return q.AndWhere(field, OpGreaterThanOrEqual, value)
+}
+
+func (q Query) AndLessThanFloat(field string, value float64) Query {
+	return q.AndWhere(field, OpLessThan, value)
+}
+
+func (q Query) AndLessThanOrEqualFloat(field string, value float64) Query {
+	return q.AndWhere(field, OpLessThanOrEqual, value)
+}
+
+func (q Query) AndCreatedAfter(t time.Time) Query {
+	return q.AndWhere("_createdOn", OpGreaterThan, t)
+}
+
+func (q Query) AndCreatedBefore(t time.Time) Query {
+	return q.AndWhere("_createdOn", OpLessThan, t)
+}
+
+func (q Query) AndUpdatedAfter(t time.Time) Query {
+	return q.AndWhere("_updatedOn", OpGreaterThan, t)
+}
+
+func (q Query) AndUpdatedBefore(t time.Time) Query {
+	return q.AndWhere("_updatedOn", OpLessThan, t)
+}
+
+func (q Query) AndStartsWith(field string, prefix string) Query {
+	return q.AndMatch(field, escapePattern(prefix)+"*")
+}
+
+func (q Query) AndEndsWith(field string, suffix string) Query {
+	return q.AndMatch(field, "*"+escapePattern(suffix))
+}
+
+func (q Query) AndContains(field string, substring string) Query {
+	return q.AndMatch(field, "*"+escapePattern(substring)+"*")
+}
+
+func (q Query) AndMatch(field string, pattern string) Query {
+	return q.AndWhere(field, OpMatch, pattern)
+}
+
+// Clone returns a copy of the query, a Query value is already a copy since it is immutable.
+func (q Query) Clone() Query {
+	return q
+}
+
+// Merge returns a query having the filters of both queries.
+// Offset, limit and sort of other win when they are set.
+func (q Query) Merge(other Query) Query {
+	if other.offset != "" {
+		q.offset = other.offset
+	}
+	if other.limit != "" {
+		q.limit = other.limit
+	}
+	if other.sort != "" {
+		q.sort = other.sort
+	}
+	if other.filters != "" {
+		if q.filters != "" {
+			q.filters += ","
+		}
+		q.filters += other.filters
+	}
+	if q.err == nil {
+		q.err = other.err
+	}
+	return q
+}
+
+// Build the query string, check Err() before sending it
+func (q Query) Build() string {
+	params := make([]string, 0, 4)
+	if q.offset != "" {
+		params = append(params, "offset="+q.offset)
+	}
+	if q.limit != "" {
+		params = append(params, "limit="+q.limit)
+	}
+	if q.sort != "" {
+		params = append(params, "sort="+escapeQueryValue(wireSort(q.sort)))
+	}
+	if q.filters != "" {
+		params = append(params, "q="+q.filters)
+	}
+	return "?" + strings.Join(params, "&")
+}
+
+// Err returns the first error occurred while building the query.
+func (q Query) Err() error {
+	return q.err
+}
+
+// Keep the first error, the invalid part is not added to the query
+func (q Query) fail(err error) Query {
+	if q.err == nil {
+		q.err = err
+	}
+	return q
+}
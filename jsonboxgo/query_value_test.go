@@ -0,0 +1,114 @@
+package jsonboxgo
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestQuery(t *testing.T) {
+	// test cases
+	base := NewQuery().AndEqual("country", "JP")
+	testCases := map[string]struct {
+		InputQuery    Query
+		ExpectedQuery string
+		ExpectedError error
+	}{
+		"Empty.": {
+			InputQuery:    NewQuery(),
+			ExpectedQuery: "?",
+		},
+		"Same order as QueryBuilder.": {
+			InputQuery:    NewQuery().AndGreaterThanOrEqualInt("age", 40).SortAsc("age").Limit(3).Offset(1),
+			ExpectedQuery: "?offset=1&limit=3&sort=age&q=age:>=40",
+		},
+		"Last wins.": {
+			InputQuery:    NewQuery().Limit(3).Limit(5).SortAsc("age").SortDesc("name").Offset(1).Offset(2),
+			ExpectedQuery: "?offset=2&limit=5&sort=-name",
+		},
+		"Base is not modified.": {
+			InputQuery:    base,
+			ExpectedQuery: "?q=country:=JP",
+		},
+		"Derived from base.": {
+			InputQuery:    base.AndStartsWith("name", "taro"),
+			ExpectedQuery: "?q=country:=JP,name:taro*",
+		},
+		"Merge.": {
+			InputQuery:    base.Limit(3).SortAsc("age").Merge(NewQuery().Limit(10).AndLessThanInt("age", 20)),
+			ExpectedQuery: "?limit=10&sort=age&q=country:=JP,age:<20",
+		},
+		"Merge invalid.": {
+			InputQuery:    base.Merge(NewQuery().AndEqual("name", "a,b")),
+			ExpectedQuery: "?q=country:=JP",
+			ExpectedError: ErrInvalidQuery,
+		},
+		"Ambiguous match pattern.": {
+			InputQuery:    NewQuery().AndMatch("name", "=taro"),
+			ExpectedQuery: "?",
+			ExpectedError: ErrInvalidQuery,
+		},
+	}
+
+	// run
+	for testCase, param := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			actual := param.InputQuery.Build()
+			expected := param.ExpectedQuery
+			if actual != expected {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+			}
+			err := param.InputQuery.Err()
+			if (param.ExpectedError == nil && err != nil) || !errors.Is(err, param.ExpectedError) {
+				t.Errorf("  Failed: err -> %v(%T), expected -> %v\n", err, err, param.ExpectedError)
+			}
+		})
+	}
+}
+
+func TestQueryComparable(t *testing.T) {
+	cache := map[Query]string{}
+	cache[NewQuery().AndEqual("country", "JP").Limit(3)] = "cached"
+	actual := cache[NewQuery().Limit(3).AndEqual("country", "JP")]
+	expected := "cached"
+	if actual != expected {
+		t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+	}
+	if NewQuery().Limit(3) == NewQuery().Limit(4) || NewQuery().Clone() != NewQuery() {
+		t.Errorf("  Failed: equality\n")
+	}
+}
+
+func TestQueryConcurrentReuse(t *testing.T) {
+	base := NewQuery().AndEqual("country", "JP")
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_ = base.Offset(i).Limit(i).AndGreaterThanInt("age", i).Build()
+		}(i)
+	}
+	wg.Wait()
+	actual := base.Build()
+	expected := "?q=country:=JP"
+	if actual != expected {
+		t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+	}
+}
+
+func TestQueryUsedByClient(t *testing.T) {
+	mockHttpClient := CreateNewTestClient(200, `[]`, 0, ``)
+	client := NewTestJsonboxClient(mockHttpClient)
+	if _, err := client.ReadByQueryWithError("users", NewQuery().Limit(1)); err != nil {
+		t.Errorf("  Failed: err -> %v(%T)\n", err, err)
+	}
+}
+
+func TestQueryBuilderLastWins(t *testing.T) {
+	actual := NewQueryBuilder().Limit(3).Offset(1).Limit(5).SortAsc("age").SortDesc("age").Build()
+	expected := "?limit=5&offset=1&sort=-age"
+	if actual != expected {
+		t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+	}
+}
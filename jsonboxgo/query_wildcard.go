@@ -0,0 +1,64 @@
+package jsonboxgo
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// OpMatch is jsonbox's plain ":" operator, the value is a case-insensitive MongoDB $regex.
+// A leading "*" drops the anchor at the start and a trailing "*" the anchor at the end.
+const OpMatch Operator = ":"
+
+// AndStartsWith filters string fields starting with the prefix, e.g. name:taro*
+func (d *DefaultQueryBuilder) AndStartsWith(field string, prefix string) QueryBuilder {
+	return d.AndMatch(field, escapePattern(prefix)+"*")
+}
+
+// AndEndsWith filters string fields ending with the suffix, e.g. name:*aro
+func (d *DefaultQueryBuilder) AndEndsWith(field string, suffix string) QueryBuilder {
+	return d.AndMatch(field, "*"+escapePattern(suffix))
+}
+
+// AndContains filters string fields containing the substring, e.g. name:*ar*
+func (d *DefaultQueryBuilder) AndContains(field string, substring string) QueryBuilder {
+	return d.AndMatch(field, "*"+escapePattern(substring)+"*")
+}
+
+// AndMatch filters string fields by the pattern as is.
+// jsonbox evaluates it as a case-insensitive regular expression anchored at both ends,
+// a leading or trailing "*" removes the anchor of that end. Other "*" repeat the preceding character.
+// Regular expression metacharacters match literally only when escaped, e.g. `\.` or `[*]` (see regexp.QuoteMeta).
+// The pattern must compile with package regexp.
+func (d *DefaultQueryBuilder) AndMatch(field string, pattern string) QueryBuilder {
+	return d.AndWhere(field, OpMatch, pattern)
+}
+
+// Escape the regular expression metacharacters, a literal "*" becomes "[*]" so it is never taken for a wildcard
+func escapePattern(value string) string {
+	parts := strings.Split(value, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return strings.Join(parts, "[*]")
+}
+
+// Compile the pattern like jsonbox, the wildcards at the ends are removed before it is used as a regular expression
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	start, end := "^", "$"
+	if strings.HasPrefix(pattern, "*") {
+		pattern, start = pattern[1:], ""
+	}
+	if strings.HasSuffix(pattern, "*") {
+		pattern, end = pattern[:len(pattern)-1], ""
+	}
+	return regexp.Compile("(?i)" + start + "(?:" + pattern + ")" + end)
+}
+
+// The pattern must be a valid regular expression
+func validatePattern(field string, pattern string) error {
+	if _, err := compilePattern(pattern); err != nil {
+		return fmt.Errorf("%w: invalid pattern %q of %q: %v", ErrInvalidQuery, pattern, field, err)
+	}
+	return nil
+}
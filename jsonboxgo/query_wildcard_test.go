@@ -0,0 +1,100 @@
+package jsonboxgo
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestQueryBuilderWildcard(t *testing.T) {
+	// test cases
+	testCases := map[string]struct {
+		InputQuery    QueryBuilder
+		ExpectedQuery string
+		ExpectedError error
+	}{
+		"Starts with.": {
+			InputQuery:    NewQueryBuilder().AndStartsWith("name", "taro"),
+			ExpectedQuery: "?q=name:taro*",
+		},
+		"Ends with.": {
+			InputQuery:    NewQueryBuilder().AndEndsWith("name", "aro"),
+			ExpectedQuery: "?q=name:*aro",
+		},
+		"Contains.": {
+			InputQuery:    NewQueryBuilder().AndContains("name", "ar"),
+			ExpectedQuery: "?q=name:*ar*",
+		},
+		"Match.": {
+			InputQuery:    NewQueryBuilder().AndMatch("name", "t*r*"),
+			ExpectedQuery: "?q=name:t*r*",
+		},
+		"Literal asterisk is escaped.": {
+			InputQuery:    NewQueryBuilder().AndStartsWith("name", "a*b"),
+			ExpectedQuery: "?q=name:a%5B*%5Db*",
+		},
+		"Literal asterisk at the end is not a wildcard.": {
+			InputQuery:    NewQueryBuilder().AndEndsWith("name", "o*"),
+			ExpectedQuery: "?q=name:*o%5B*%5D",
+		},
+		"Regular expression metacharacters are escaped.": {
+			InputQuery:    NewQueryBuilder().AndContains("mail", "a.b+(c)?"),
+			ExpectedQuery: "?q=mail:*a%5C.b%5C%2B%5C%28c%5C%29%5C%3F*",
+		},
+		"Literal backslash is escaped.": {
+			InputQuery:    NewQueryBuilder().AndContains("path", `a\b`),
+			ExpectedQuery: "?q=path:*a%5C%5Cb*",
+		},
+		"Combined with other filters.": {
+			InputQuery:    NewQueryBuilder().AndEqual("country", "JP").AndStartsWith("name", "taro yamada"),
+			ExpectedQuery: "?q=country:=JP,name:taro%20yamada*",
+		},
+		"Where with match operator.": {
+			InputQuery:    NewQueryBuilder().AndWhere("name", OpMatch, "taro*"),
+			ExpectedQuery: "?q=name:taro*",
+		},
+		"Match operator with number.": {
+			InputQuery:    NewQueryBuilder().AndWhere("age", OpMatch, 40),
+			ExpectedQuery: "?",
+			ExpectedError: ErrInvalidQuery,
+		},
+		"Match with regular expression.": {
+			InputQuery:    NewQueryBuilder().AndMatch("name", `ta\.r[aeiou]`),
+			ExpectedQuery: "?q=name:ta%5C.r%5Baeiou%5D",
+		},
+		"Invalid regular expression.": {
+			InputQuery:    NewQueryBuilder().AndMatch("name", "ta(ro"),
+			ExpectedQuery: "?",
+			ExpectedError: ErrInvalidQuery,
+		},
+		"Trailing backslash.": {
+			InputQuery:    NewQueryBuilder().AndMatch("name", `taro\`),
+			ExpectedQuery: "?",
+			ExpectedError: ErrInvalidQuery,
+		},
+		"Escaped asterisk at the end is taken for a wildcard.": {
+			InputQuery:    NewQueryBuilder().AndMatch("name", `taro\*`),
+			ExpectedQuery: "?",
+			ExpectedError: ErrInvalidQuery,
+		},
+		"Comma.": {
+			InputQuery:    NewQueryBuilder().AndContains("name", "a,b"),
+			ExpectedQuery: "?",
+			ExpectedError: ErrInvalidQuery,
+		},
+	}
+
+	// run
+	for testCase, param := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			actual := param.InputQuery.Build()
+			expected := param.ExpectedQuery
+			if actual != expected {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+			}
+			err := param.InputQuery.Err()
+			if (param.ExpectedError == nil && err != nil) || !errors.Is(err, param.ExpectedError) {
+				t.Errorf("  Failed: err -> %v(%T), expected -> %v\n", err, err, param.ExpectedError)
+			}
+		})
+	}
+}
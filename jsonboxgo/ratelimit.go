@@ -0,0 +1,202 @@
+package jsonboxgo
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RateLimiterConfig configures RateLimiter.
+type RateLimiterConfig struct {
+	// Rate is the number of requests allowed per second.
+	Rate float64
+	// Burst is the number of requests allowed at once.
+	Burst int
+	// FailFast returns ErrRateLimitExceeded instead of waiting for a token.
+	FailFast bool
+	// MinRate is the lower bound when the rate is lowered by 429 responses. Defaults to Rate / 10.
+	MinRate float64
+}
+
+// RateLimiterStats is the snapshot of RateLimiter.
+type RateLimiterStats struct {
+	Rate           float64
+	ConfiguredRate float64
+	Burst          int
+	Available      float64
+	Waiting        int
+	Allowed        uint64
+	Rejected       uint64
+	Throttled      uint64
+	PausedUntil    time.Time
+}
+
+// RateLimiter is a token bucket limiter, it can be shared between goroutines and clients of the same box.
+// The rate is halved on every 429 response and recovers gradually on successful responses.
+type RateLimiter struct {
+	mutex       sync.Mutex
+	config      RateLimiterConfig
+	rate        float64
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+	waiting     int
+	allowed     uint64
+	rejected    uint64
+	throttled   uint64
+}
+
+// Create new RateLimiter
+func NewRateLimiter(config RateLimiterConfig) (*RateLimiter, error) {
+	if config.Rate <= 0 || math.IsInf(config.Rate, 0) || math.IsNaN(config.Rate) {
+		return nil, fmt.Errorf("jsonboxgo: rate must be positive: %v", config.Rate)
+	}
+	if config.Burst < 1 {
+		return nil, fmt.Errorf("jsonboxgo: burst must be at least 1: %d", config.Burst)
+	}
+	if config.MinRate < 0 || config.MinRate > config.Rate {
+		return nil, fmt.Errorf("jsonboxgo: min rate must be between 0 and rate: %v", config.MinRate)
+	}
+	if config.MinRate == 0 {
+		config.MinRate = config.Rate / 10
+	}
+	return &RateLimiter{
+		config: config,
+		rate:   config.Rate,
+		tokens: float64(config.Burst),
+		last:   time.Now(),
+	}, nil
+}
+
+// WithRateLimiter limits requests of the client, the same limiter can be passed to several clients.
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(c *DefaultClient) error {
+		if limiter == nil {
+			return fmt.Errorf("jsonboxgo: rate limiter must not be nil")
+		}
+		c.rateLimiter = limiter
+		return nil
+	}
+}
+
+// Wait blocks until a request is allowed, or returns ErrRateLimitExceeded in fail fast mode.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	l.mutex.Lock()
+	now := time.Now()
+	l.refill(now)
+	if l.tokens >= 1 && !now.Before(l.last) {
+		l.tokens--
+		l.allowed++
+		l.mutex.Unlock()
+		return nil
+	}
+	if l.config.FailFast {
+		l.rejected++
+		l.mutex.Unlock()
+		return ErrRateLimitExceeded
+	}
+	// reserve the token, then wait until it is refilled
+	l.tokens--
+	wait := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	if l.last.After(now) {
+		wait += l.last.Sub(now)
+	}
+	l.waiting++
+	l.throttled++
+	l.mutex.Unlock()
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		l.mutex.Lock()
+		l.tokens++
+		l.waiting--
+		l.mutex.Unlock()
+		return fmt.Errorf("jsonboxgo: waiting rate limiter canceled: %w", ctx.Err())
+	case <-timer.C:
+		l.mutex.Lock()
+		l.waiting--
+		l.allowed++
+		l.mutex.Unlock()
+		return nil
+	}
+}
+
+// Allow reports whether a request is allowed now without waiting.
+func (l *RateLimiter) Allow() bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	now := time.Now()
+	l.refill(now)
+	if l.tokens >= 1 && !now.Before(l.last) {
+		l.tokens--
+		l.allowed++
+		return true
+	}
+	l.rejected++
+	return false
+}
+
+// Stats returns the current budget.
+func (l *RateLimiter) Stats() RateLimiterStats {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.refill(time.Now())
+	return RateLimiterStats{
+		Rate:           l.rate,
+		ConfiguredRate: l.config.Rate,
+		Burst:          l.config.Burst,
+		Available:      math.Max(l.tokens, 0),
+		Waiting:        l.waiting,
+		Allowed:        l.allowed,
+		Rejected:       l.rejected,
+		Throttled:      l.throttled,
+		PausedUntil:    l.pausedUntil,
+	}
+}
+
+// Adapt the rate to the responded status code and rate limit headers
+func (l *RateLimiter) observe(statusCode int, header http.Header) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	now := time.Now()
+	l.refill(now)
+	if statusCode == http.StatusTooManyRequests {
+		l.rate = math.Max(l.rate/2, l.config.MinRate)
+		delay, ok := retryAfter(header, now)
+		if !ok {
+			delay = time.Duration(float64(time.Second) / l.rate)
+		}
+		l.pause(now.Add(delay))
+		return
+	}
+	if delay, ok := retryAfter(header, now); ok && header.Get("X-RateLimit-Remaining") == "0" {
+		l.pause(now.Add(delay))
+	}
+	// recover the rate additively
+	l.rate = math.Min(l.rate+l.config.Rate/20, l.config.Rate)
+}
+
+// Drain tokens and stop refilling until the time
+func (l *RateLimiter) pause(until time.Time) {
+	l.tokens = math.Min(l.tokens, 0)
+	if until.After(l.last) {
+		l.last = until
+	}
+	if until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+}
+
+// Add tokens for the elapsed time
+func (l *RateLimiter) refill(now time.Time) {
+	if !now.After(l.last) {
+		return
+	}
+	l.tokens = math.Min(l.tokens+now.Sub(l.last).Seconds()*l.rate, float64(l.config.Burst))
+	l.last = now
+}
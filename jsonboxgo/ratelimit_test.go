@@ -0,0 +1,137 @@
+package jsonboxgo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewRateLimiterValidation(t *testing.T) {
+	// test cases
+	testCases := map[string]struct {
+		InputConfig   RateLimiterConfig
+		ExpectedError bool
+	}{
+		"Valid.":          {InputConfig: RateLimiterConfig{Rate: 10, Burst: 1}},
+		"Zero rate.":      {InputConfig: RateLimiterConfig{Rate: 0, Burst: 1}, ExpectedError: true},
+		"Zero burst.":     {InputConfig: RateLimiterConfig{Rate: 10, Burst: 0}, ExpectedError: true},
+		"Too high min.":   {InputConfig: RateLimiterConfig{Rate: 10, Burst: 1, MinRate: 11}, ExpectedError: true},
+		"Negative min.":   {InputConfig: RateLimiterConfig{Rate: 10, Burst: 1, MinRate: -1}, ExpectedError: true},
+		"Valid min rate.": {InputConfig: RateLimiterConfig{Rate: 10, Burst: 1, MinRate: 1}},
+	}
+
+	// run
+	for testCase, param := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			_, err := NewRateLimiter(param.InputConfig)
+			if (err != nil) != param.ExpectedError {
+				t.Errorf("  Failed: err -> %v(%T), expectedError -> %v\n", err, err, param.ExpectedError)
+			}
+		})
+	}
+}
+
+func TestRateLimiterFailFast(t *testing.T) {
+	limiter, _ := NewRateLimiter(RateLimiterConfig{Rate: 0.001, Burst: 2, FailFast: true})
+	requests := make([]*http.Request, 0)
+	mockHttpClient := CreateNewSequenceTestClient([]TestResponse{{StatusCode: 200, Body: `[]`}, {StatusCode: 200, Body: `[]`}}, &requests)
+	client, _ := NewClient("https://test.com", "box_test", WithHTTPClient(mockHttpClient), WithRateLimiter(limiter))
+	for i := 0; i < 2; i++ {
+		if _, err := client.ReadAllWithError("users"); err != nil {
+			t.Fatalf("  Failed: err -> %v(%T)\n", err, err)
+		}
+	}
+	_, err := client.ReadAllWithError("users")
+	if !errors.Is(err, ErrRateLimitExceeded) {
+		t.Errorf("  Failed: err -> %v(%T), expected -> %v\n", err, err, ErrRateLimitExceeded)
+	}
+	stats := limiter.Stats()
+	if stats.Allowed != 2 || stats.Rejected != 1 || len(requests) != 2 {
+		t.Errorf("  Failed: stats -> %+v, requestCount -> %v\n", stats, len(requests))
+	}
+}
+
+func TestRateLimiterFailFastLegacy(t *testing.T) {
+	limiter, _ := NewRateLimiter(RateLimiterConfig{Rate: 0.001, Burst: 1, FailFast: true})
+	requests := make([]*http.Request, 0)
+	mockHttpClient := CreateNewSequenceTestClient([]TestResponse{{StatusCode: 200, Body: `{"_id":"id001"}`}}, &requests)
+	client, _ := NewClient("https://test.com", "box_test", WithHTTPClient(mockHttpClient), WithRateLimiter(limiter))
+	if actual := client.Create("users", User{Name: "taro"}); string(actual) != `{"_id":"id001"}` {
+		t.Fatalf("  Failed: actual -> %v(%T)\n", string(actual), actual)
+	}
+	// rejected calls return instead of exiting the process
+	if actual := client.Create("users", User{Name: "jiro"}); actual != nil {
+		t.Errorf("  Failed: actual -> %v(%T), expected -> nil\n", actual, actual)
+	}
+	if actual := client.ReadAll("users"); actual != nil {
+		t.Errorf("  Failed: actual -> %v(%T), expected -> nil\n", actual, actual)
+	}
+	if _, found := client.Read("users", "id001"); found {
+		t.Errorf("  Failed: found -> %v, expected -> false\n", found)
+	}
+	if _, updated := client.Update("users", "id001", User{Name: "jiro"}); updated {
+		t.Errorf("  Failed: updated -> %v, expected -> false\n", updated)
+	}
+	if _, deleted := client.Delete("users", "id001"); deleted {
+		t.Errorf("  Failed: deleted -> %v, expected -> false\n", deleted)
+	}
+	if len(requests) != 1 {
+		t.Errorf("  Failed: requestCount -> %v, expected -> 1\n", len(requests))
+	}
+}
+
+func TestRateLimiterWaitShared(t *testing.T) {
+	limiter, _ := NewRateLimiter(RateLimiterConfig{Rate: 200, Burst: 1})
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := limiter.Wait(context.Background()); err != nil {
+				t.Errorf("  Failed: err -> %v(%T)\n", err, err)
+			}
+		}()
+	}
+	wg.Wait()
+	// 1 burst token + 4 tokens refilled at 200/s
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Errorf("  Failed: elapsed -> %v, expected -> >= 20ms\n", elapsed)
+	}
+	stats := limiter.Stats()
+	if stats.Allowed != 5 || stats.Throttled != 4 || stats.Waiting != 0 {
+		t.Errorf("  Failed: stats -> %+v\n", stats)
+	}
+}
+
+func TestRateLimiterWaitCanceled(t *testing.T) {
+	limiter, _ := NewRateLimiter(RateLimiterConfig{Rate: 0.001, Burst: 1})
+	limiter.Allow()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("  Failed: err -> %v(%T), expected -> %v\n", err, err, context.DeadlineExceeded)
+	}
+}
+
+func TestRateLimiterAdaptsTo429(t *testing.T) {
+	limiter, _ := NewRateLimiter(RateLimiterConfig{Rate: 100, Burst: 10, FailFast: true})
+	requests := make([]*http.Request, 0)
+	mockHttpClient := CreateNewSequenceTestClient([]TestResponse{
+		{StatusCode: 429, Body: ``, Header: map[string]string{"Retry-After": "60"}},
+	}, &requests)
+	client, _ := NewClient("https://test.com", "box_test", WithHTTPClient(mockHttpClient), WithRateLimiter(limiter))
+	if _, err := client.ReadAllWithError("users"); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("  Failed: err -> %v(%T), expected -> %v\n", err, err, ErrRateLimited)
+	}
+	stats := limiter.Stats()
+	if stats.Rate != 50 || stats.Available != 0 || time.Until(stats.PausedUntil) < 59*time.Second {
+		t.Errorf("  Failed: stats -> %+v\n", stats)
+	}
+	if limiter.Allow() {
+		t.Errorf("  Failed: allowed while paused\n")
+	}
+}
@@ -0,0 +1,207 @@
+package jsonboxgo
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	mathrand "math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy configures automatic retries of failed requests.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts including the first one.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry, it is doubled on every retry.
+	BaseDelay time.Duration
+	// MaxDelay caps the exponential delay. Zero means no cap.
+	// When the server asks to wait longer than MaxDelay, the failure is returned without retrying.
+	MaxDelay time.Duration
+	// Jitter randomly shortens each delay by up to this fraction (0.0 - 1.0).
+	Jitter float64
+	// RetryableStatusCodes are the responded status codes to retry.
+	RetryableStatusCodes []int
+	// RetryOnNetworkError retries transport failures such as connection resets and timeouts.
+	RetryOnNetworkError bool
+	// RetryableErrors are retried when the failure matches one of them by errors.Is.
+	RetryableErrors []error
+	// IdempotencyKeyHeader enables retries of POST requests.
+	// A generated key is sent in this header and reused by every attempt of the same call.
+	// Neither jsonbox nor jsonboxd deduplicates by the header, so set it only behind a server or proxy which does,
+	// otherwise a retried create whose first attempt was stored makes a duplicate record.
+	IdempotencyKeyHeader string
+}
+
+// DefaultRetryPolicy returns the policy retrying rate limits, 5xx responses and network errors 3 times.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:          3,
+		BaseDelay:            200 * time.Millisecond,
+		MaxDelay:             5 * time.Second,
+		Jitter:               0.2,
+		RetryableStatusCodes: []int{http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+		RetryOnNetworkError:  true,
+	}
+}
+
+// WithRetryPolicy enables automatic retries.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *DefaultClient) error {
+		if policy.MaxAttempts < 1 {
+			return fmt.Errorf("jsonboxgo: max attempts must be at least 1: %d", policy.MaxAttempts)
+		}
+		if policy.BaseDelay < 0 || policy.MaxDelay < 0 {
+			return errors.New("jsonboxgo: retry delays must not be negative")
+		}
+		if policy.MaxDelay > 0 && policy.MaxDelay < policy.BaseDelay {
+			return errors.New("jsonboxgo: max delay must not be shorter than base delay")
+		}
+		if policy.Jitter < 0 || policy.Jitter > 1 {
+			return fmt.Errorf("jsonboxgo: jitter must be between 0 and 1: %v", policy.Jitter)
+		}
+		if policy.IdempotencyKeyHeader != "" && !isHeaderName(policy.IdempotencyKeyHeader) {
+			return fmt.Errorf("jsonboxgo: invalid header name %q", policy.IdempotencyKeyHeader)
+		}
+		c.retryPolicy = &policy
+		return nil
+	}
+}
+
+// Send request, failed attempts are retried according to the retry policy
+func (c DefaultClient) doWithRetry(ctx context.Context, httpMethod string, requestUrl string, requestBody []byte) ([]byte, error) {
+	policy := c.retryPolicy
+	if policy == nil || policy.MaxAttempts == 1 {
+		return c.doOnce(ctx, httpMethod, requestUrl, requestBody, nil)
+	}
+	var header http.Header
+	if httpMethod == http.MethodPost {
+		// a create is not idempotent, retry it only when the server can deduplicate it
+		if policy.IdempotencyKeyHeader == "" {
+			return c.doOnce(ctx, httpMethod, requestUrl, requestBody, nil)
+		}
+		header = http.Header{}
+		header.Set(policy.IdempotencyKeyHeader, newIdempotencyKey())
+	}
+	for attempt := 1; ; attempt++ {
+		body, err := c.doOnce(ctx, httpMethod, requestUrl, requestBody, header)
+		if err == nil || attempt >= policy.MaxAttempts || ctx.Err() != nil || !policy.shouldRetry(err) {
+			return body, err
+		}
+		delay, ok := policy.delay(attempt, err)
+		if !ok {
+			return body, err
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("jsonboxgo: retry canceled: %w (last error: %v)", ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
+
+// Report whether the failure should be retried
+func (p *RetryPolicy) shouldRetry(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		for _, statusCode := range p.RetryableStatusCodes {
+			if apiErr.StatusCode == statusCode {
+				return true
+			}
+		}
+	}
+	for _, retryableErr := range p.RetryableErrors {
+		if errors.Is(err, retryableErr) {
+			return true
+		}
+	}
+	if p.RetryOnNetworkError && apiErr == nil {
+		var netErr net.Error
+		return errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded)
+	}
+	return false
+}
+
+// Calculate the delay before the next attempt, the server's instruction has priority.
+// It reports false when the server's delay exceeds MaxDelay.
+func (p *RetryPolicy) delay(attempt int, err error) (time.Duration, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		if serverDelay, ok := retryAfter(apiErr.Header, time.Now()); ok {
+			// waiting less than the server asks would only be rejected again
+			return serverDelay, p.MaxDelay == 0 || serverDelay <= p.MaxDelay
+		}
+	}
+	delay := p.BaseDelay
+	for i := 1; i < attempt && (p.MaxDelay == 0 || delay < p.MaxDelay); i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		delay -= time.Duration(p.Jitter * mathrand.Float64() * float64(delay))
+	}
+	return delay, true
+}
+
+// Read the delay from Retry-After or X-RateLimit-* headers
+func retryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	if value := header.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second, true
+		}
+		if date, err := http.ParseTime(value); err == nil {
+			return nonNegative(date.Sub(now)), true
+		}
+	}
+	if header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, ok := rateLimitReset(header, now); ok {
+			return nonNegative(reset.Sub(now)), true
+		}
+	}
+	return 0, false
+}
+
+// Read X-RateLimit-Reset, which is either epoch seconds, seconds from now or a http date
+func rateLimitReset(header http.Header, now time.Time) (time.Time, bool) {
+	value := header.Get("X-RateLimit-Reset")
+	if value == "" {
+		return time.Time{}, false
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		// values smaller than a year of seconds are relative
+		if seconds < 365*24*60*60 {
+			return now.Add(time.Duration(seconds) * time.Second), true
+		}
+		return time.Unix(seconds, 0), true
+	}
+	for _, layout := range []string{http.TimeFormat, time.RFC1123, time.RFC3339} {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func nonNegative(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// Generate random key for the idempotency key header
+func newIdempotencyKey() string {
+	key := make([]byte, 16)
+	if _, err := rand.Read(key); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(key)
+}
@@ -0,0 +1,258 @@
+package jsonboxgo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type TestResponse struct {
+	StatusCode int
+	Body       string
+	Header     map[string]string
+}
+
+func CreateNewSequenceTestClient(responses []TestResponse, requests *[]*http.Request) *http.Client {
+	return NewTestClient(func(req *http.Request) *http.Response {
+		response := responses[len(*requests)]
+		*requests = append(*requests, req)
+		header := make(http.Header)
+		for key, value := range response.Header {
+			header.Set(key, value)
+		}
+		return &http.Response{
+			StatusCode: response.StatusCode,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(response.Body)),
+			Header:     header,
+		}
+	})
+}
+
+func TestRetry(t *testing.T) {
+	// test cases
+	testPolicy := RetryPolicy{
+		MaxAttempts:          3,
+		BaseDelay:            time.Millisecond,
+		MaxDelay:             5 * time.Millisecond,
+		RetryableStatusCodes: []int{429, 503},
+	}
+	idempotentPolicy := testPolicy
+	idempotentPolicy.IdempotencyKeyHeader = "Idempotency-Key"
+	testCases := map[string]struct {
+		InputPolicy           RetryPolicy
+		InputOperation        func(Client) ([]byte, error)
+		InputResponses        []TestResponse
+		ExpectedRespondedBody string
+		ExpectedError         error
+		ExpectedRequestCount  int
+	}{
+		"Retried and succeeded.": {
+			InputPolicy: testPolicy,
+			InputOperation: func(c Client) ([]byte, error) {
+				return c.ReadAllWithError("users")
+			},
+			InputResponses: []TestResponse{
+				{StatusCode: 503, Body: `{"message":"Service Unavailable"}`},
+				{StatusCode: 429, Body: `{"message":"Too many requests"}`, Header: map[string]string{"Retry-After": "0"}},
+				{StatusCode: 200, Body: `[]`},
+			},
+			ExpectedRespondedBody: `[]`,
+			ExpectedRequestCount:  3,
+		},
+		"Gave up after max attempts.": {
+			InputPolicy: testPolicy,
+			InputOperation: func(c Client) ([]byte, error) {
+				return c.ReadAllWithError("users")
+			},
+			InputResponses: []TestResponse{
+				{StatusCode: 503, Body: ``},
+				{StatusCode: 503, Body: ``},
+				{StatusCode: 503, Body: ``},
+			},
+			ExpectedError:        &APIError{},
+			ExpectedRequestCount: 3,
+		},
+		"Gave up when Retry-After exceeds max delay.": {
+			InputPolicy: testPolicy,
+			InputOperation: func(c Client) ([]byte, error) {
+				return c.ReadAllWithError("users")
+			},
+			InputResponses: []TestResponse{
+				{StatusCode: 429, Body: `{"message":"Too many requests"}`, Header: map[string]string{"Retry-After": "3600"}},
+				{StatusCode: 200, Body: `[]`},
+			},
+			ExpectedError:        ErrRateLimited,
+			ExpectedRequestCount: 1,
+		},
+		"Not retryable status code.": {
+			InputPolicy: testPolicy,
+			InputOperation: func(c Client) ([]byte, error) {
+				return c.DeleteWithError("users", "id001")
+			},
+			InputResponses: []TestResponse{
+				{StatusCode: 400, Body: `{"message":"Invalid record Id"}`},
+			},
+			ExpectedError:        ErrInvalidID,
+			ExpectedRequestCount: 1,
+		},
+		"Create is not retried without idempotency key.": {
+			InputPolicy: testPolicy,
+			InputOperation: func(c Client) ([]byte, error) {
+				return c.CreateWithError("users", User{Name: "taro"})
+			},
+			InputResponses: []TestResponse{
+				{StatusCode: 503, Body: ``},
+			},
+			ExpectedError:        &APIError{},
+			ExpectedRequestCount: 1,
+		},
+		"Create is retried with idempotency key.": {
+			InputPolicy: idempotentPolicy,
+			InputOperation: func(c Client) ([]byte, error) {
+				return c.CreateWithError("users", User{Name: "taro"})
+			},
+			InputResponses: []TestResponse{
+				{StatusCode: 503, Body: ``},
+				{StatusCode: 200, Body: `{"_id":"id001","name":"taro"}`},
+			},
+			ExpectedRespondedBody: `{"_id":"id001","name":"taro"}`,
+			ExpectedRequestCount:  2,
+		},
+	}
+
+	// run
+	for testCase, param := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			requests := make([]*http.Request, 0)
+			mockHttpClient := CreateNewSequenceTestClient(param.InputResponses, &requests)
+			client, err := NewClient("https://test.com", "box_test", WithHTTPClient(mockHttpClient), WithRetryPolicy(param.InputPolicy))
+			if err != nil {
+				t.Fatalf("  Failed: err -> %v(%T)\n", err, err)
+			}
+			result, err := param.InputOperation(client)
+			actual := string(result)
+			expected := param.ExpectedRespondedBody
+			if actual != expected {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+			}
+			var apiErr *APIError
+			if _, ok := param.ExpectedError.(*APIError); ok && !errors.As(err, &apiErr) {
+				t.Errorf("  Failed: err -> %v(%T), expected -> *APIError\n", err, err)
+			} else if !ok && param.ExpectedError != nil && !errors.Is(err, param.ExpectedError) {
+				t.Errorf("  Failed: err -> %v(%T), expected -> %v\n", err, err, param.ExpectedError)
+			} else if param.ExpectedError == nil && err != nil {
+				t.Errorf("  Failed: err -> %v(%T), expected -> nil\n", err, err)
+			}
+			if len(requests) != param.ExpectedRequestCount {
+				t.Errorf("  Failed: requestCount -> %v, expected -> %v\n", len(requests), param.ExpectedRequestCount)
+			}
+			if param.InputPolicy.IdempotencyKeyHeader != "" {
+				key := requests[0].Header.Get(param.InputPolicy.IdempotencyKeyHeader)
+				for _, req := range requests {
+					if key == "" || req.Header.Get(param.InputPolicy.IdempotencyKeyHeader) != key {
+						t.Errorf("  Failed: idempotency key -> %v, expected -> %v\n", req.Header.Get(param.InputPolicy.IdempotencyKeyHeader), key)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRetryCanceledWhileWaiting(t *testing.T) {
+	requests := make([]*http.Request, 0)
+	mockHttpClient := CreateNewSequenceTestClient([]TestResponse{
+		{StatusCode: 429, Body: ``, Header: map[string]string{"Retry-After": "4"}},
+		{StatusCode: 200, Body: `[]`},
+	}, &requests)
+	client, _ := NewClient("https://test.com", "box_test", WithHTTPClient(mockHttpClient), WithRetryPolicy(DefaultRetryPolicy()))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := client.ReadAllContext(ctx, "users")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("  Failed: err -> %v(%T), expected -> %v\n", err, err, context.DeadlineExceeded)
+	}
+	if len(requests) != 1 {
+		t.Errorf("  Failed: requestCount -> %v, expected -> 1\n", len(requests))
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	// test cases
+	now := time.Now()
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: 5 * time.Second}
+	testCases := map[string]struct {
+		InputAttempt  int
+		InputHeader   map[string]string
+		ExpectedDelay time.Duration
+		ExpectedRetry bool
+	}{
+		"First retry.": {
+			InputAttempt:  1,
+			ExpectedDelay: 100 * time.Millisecond,
+			ExpectedRetry: true,
+		},
+		"Second retry.": {
+			InputAttempt:  2,
+			ExpectedDelay: 200 * time.Millisecond,
+			ExpectedRetry: true,
+		},
+		"Capped by max delay.": {
+			InputAttempt:  8,
+			ExpectedDelay: 5 * time.Second,
+			ExpectedRetry: true,
+		},
+		"Retry-After seconds.": {
+			InputAttempt:  1,
+			InputHeader:   map[string]string{"Retry-After": "2"},
+			ExpectedDelay: 2 * time.Second,
+			ExpectedRetry: true,
+		},
+		"Retry-After longer than max delay.": {
+			InputAttempt:  1,
+			InputHeader:   map[string]string{"Retry-After": "3600"},
+			ExpectedDelay: time.Hour,
+			ExpectedRetry: false,
+		},
+		"X-RateLimit-Reset seconds.": {
+			InputAttempt:  1,
+			InputHeader:   map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "3"},
+			ExpectedDelay: 3 * time.Second,
+			ExpectedRetry: true,
+		},
+		"X-RateLimit-Reset longer than max delay.": {
+			InputAttempt:  1,
+			InputHeader:   map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "600"},
+			ExpectedDelay: 10 * time.Minute,
+			ExpectedRetry: false,
+		},
+		"X-RateLimit-Reset ignored while remaining.": {
+			InputAttempt:  1,
+			InputHeader:   map[string]string{"X-RateLimit-Remaining": "5", "X-RateLimit-Reset": "3"},
+			ExpectedDelay: 100 * time.Millisecond,
+			ExpectedRetry: true,
+		},
+	}
+
+	// run
+	for testCase, param := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			header := make(http.Header)
+			for key, value := range param.InputHeader {
+				header.Set(key, value)
+			}
+			actual, actualRetry := policy.delay(param.InputAttempt, &APIError{StatusCode: 429, Header: header})
+			if actualRetry != param.ExpectedRetry {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actualRetry, actualRetry, param.ExpectedRetry, param.ExpectedRetry)
+			}
+			expected := param.ExpectedDelay
+			// relative headers are resolved against time.Now()
+			if actual > expected || actual < expected-time.Since(now)-time.Second {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+			}
+		})
+	}
+}
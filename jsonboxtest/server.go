@@ -0,0 +1,66 @@
+// Package jsonboxtest provides an in-memory fake jsonbox server for end-to-end tests of jsonboxgo.
+package jsonboxtest
+
+import (
+	"net/http/httptest"
+	"time"
+
+	"github.com/xshoji/jsonbox-go/internal/server"
+	"github.com/xshoji/jsonbox-go/jsonboxgo"
+)
+
+// Server is a fake jsonbox server backed by httptest.Server, point jsonboxgo.NewClient at its URL.
+//
+// It serves the same API as cmd/jsonboxd without storing the records on disk.
+// Records get an ObjectID-like "_id" and "_createdOn" (and "_updatedOn" after PUT) in milliseconds,
+// and "_createdOn" strictly increases so that the default sort "-_createdOn" is stable.
+// Keys starting with "_" in a posted record are dropped like jsonbox.
+// The filters, sort, offset and limit of GET are evaluated by jsonboxgo.Matcher.
+type Server struct {
+	*httptest.Server
+	handler *server.Handler
+}
+
+// Option configures the Server.
+type Option func(*[]server.Option)
+
+// WithClock sets the clock of "_createdOn" and "_updatedOn", time.Now by default.
+func WithClock(now func() time.Time) Option {
+	return func(opts *[]server.Option) {
+		*opts = append(*opts, server.WithClock(now))
+	}
+}
+
+// WithLimits sets the size limits of a record and of a box in bytes, 0 is no limit.
+// A record is limited to 10KB and a box is not limited by default.
+func WithLimits(maxRecordSize int, maxBoxSize int) Option {
+	return func(opts *[]server.Option) {
+		*opts = append(*opts, server.WithMaxRecordSize(maxRecordSize), server.WithMaxBoxSize(maxBoxSize))
+	}
+}
+
+// Create new Server and start it, Close it after the test.
+func NewServer(opts ...Option) *Server {
+	serverOptions := make([]server.Option, 0)
+	for _, opt := range opts {
+		opt(&serverOptions)
+	}
+	// New fails only on the data directory, which is not set
+	handler, _ := server.New(serverOptions...)
+	return &Server{Server: httptest.NewServer(handler), handler: handler}
+}
+
+// NewClient creates a jsonboxgo.Client of the box on this server.
+func (s *Server) NewClient(boxId string, opts ...jsonboxgo.Option) (jsonboxgo.Client, error) {
+	return jsonboxgo.NewClient(s.URL, boxId, opts...)
+}
+
+// Records returns the records of the collection in the order they were created, "" is every record of the box.
+func (s *Server) Records(boxId string, collection string) [][]byte {
+	return s.handler.Records(boxId, collection)
+}
+
+// Reset removes every record of every box.
+func (s *Server) Reset() {
+	_ = s.handler.Reset()
+}
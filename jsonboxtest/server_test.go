@@ -0,0 +1,353 @@
+package jsonboxtest
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xshoji/jsonbox-go/jsonboxgo"
+)
+
+const testBoxId = "box_0123456789abcdefghij"
+
+type User struct {
+	jsonboxgo.Meta
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+// Create new Server with a fixed clock and a client of testBoxId
+func newTestServer(t *testing.T) (*Server, jsonboxgo.Client) {
+	t.Helper()
+	now := time.Date(2020, 4, 26, 16, 26, 13, 0, time.UTC)
+	server := NewServer(WithClock(func() time.Time { return now }))
+	t.Cleanup(server.Close)
+	client, err := server.NewClient(testBoxId)
+	if err != nil {
+		t.Fatalf("  Failed: NewClient() -> %v\n", err)
+	}
+	return server, client
+}
+
+func names(t *testing.T, body []byte) string {
+	t.Helper()
+	users := make([]User, 0)
+	if err := json.Unmarshal(body, &users); err != nil {
+		t.Fatalf("  Failed: json.Unmarshal() -> %v, body -> %s\n", err, body)
+	}
+	result := make([]string, 0, len(users))
+	for _, user := range users {
+		result = append(result, user.Name)
+	}
+	return strings.Join(result, ",")
+}
+
+func TestCreate(t *testing.T) {
+	server, client := newTestServer(t)
+	body, err := client.CreateWithError("users", map[string]interface{}{"name": "taro", "age": 20, "_id": "ignored"})
+	if err != nil {
+		t.Fatalf("  Failed: CreateWithError() -> %v\n", err)
+	}
+	var user User
+	_ = json.Unmarshal(body, &user)
+	if !regexp.MustCompile(`^[0-9a-f]{24}$`).MatchString(user.Id) {
+		t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", user.Id, user.Id, "24 hex characters", "")
+	}
+	actual, expected := user.CreatedOn, "2020-04-26T16:26:13.000Z"
+	if actual != expected {
+		t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+	}
+	actual, expected = string(body), `{"_id":"`+user.Id+`","age":20,"name":"taro","_createdOn":"2020-04-26T16:26:13.000Z"}`
+	if actual != expected {
+		t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+	}
+
+	created, err := client.CreateMany("users", []interface{}{User{Name: "jiro", Age: 30}, User{Name: "saburo", Age: 40}})
+	if err != nil {
+		t.Fatalf("  Failed: CreateMany() -> %v\n", err)
+	}
+	actualCount, expectedCount := len(created), 2
+	if actualCount != expectedCount {
+		t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actualCount, actualCount, expectedCount, expectedCount)
+	}
+	var second User
+	_ = json.Unmarshal(created[1], &second)
+	actual, expected = second.CreatedOn, "2020-04-26T16:26:13.002Z"
+	if actual != expected {
+		t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+	}
+	actualCount, expectedCount = len(server.Records(testBoxId, "users")), 3
+	if actualCount != expectedCount {
+		t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actualCount, actualCount, expectedCount, expectedCount)
+	}
+}
+
+func TestReadByQuery(t *testing.T) {
+	_, client := newTestServer(t)
+	_, err := client.CreateMany("users", []interface{}{
+		User{Name: "taro", Age: 20},
+		User{Name: "jiro", Age: 30},
+		User{Name: "saburo", Age: 40},
+		User{Name: "shiro", Age: 50},
+	})
+	if err != nil {
+		t.Fatalf("  Failed: CreateMany() -> %v\n", err)
+	}
+	_ = client.Create("pets", map[string]interface{}{"name": "pochi"})
+
+	// test cases
+	testCases := map[string]struct {
+		InputQuery jsonboxgo.Querier
+		Expected   string
+	}{
+		"Newest first by default.": {
+			InputQuery: jsonboxgo.NewQueryBuilder(),
+			Expected:   "shiro,saburo,jiro,taro",
+		},
+		"Filter by q.": {
+			InputQuery: jsonboxgo.NewQueryBuilder().AndGreaterThanOrEqualInt("age", 30).AndLessThanInt("age", 50),
+			Expected:   "saburo,jiro",
+		},
+		"Filter by wildcard.": {
+			InputQuery: jsonboxgo.NewQueryBuilder().AndEndsWith("name", "ro"),
+			Expected:   "shiro,saburo,jiro,taro",
+		},
+		"Sort, offset and limit.": {
+			InputQuery: jsonboxgo.NewQueryBuilder().SortAsc("age").Offset(1).Limit(2),
+			Expected:   "jiro,saburo",
+		},
+		"Sort descending.": {
+			InputQuery: jsonboxgo.NewQueryBuilder().SortDesc("name"),
+			Expected:   "taro,shiro,saburo,jiro",
+		},
+	}
+
+	// run
+	for testCase, param := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			body, err := client.ReadByQueryWithError("users", param.InputQuery)
+			if err != nil {
+				t.Fatalf("  Failed: ReadByQueryWithError() -> %v\n", err)
+			}
+			actual := names(t, body)
+			expected := param.Expected
+			if actual != expected {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+			}
+		})
+	}
+}
+
+func TestUpdateAndDelete(t *testing.T) {
+	_, client := newTestServer(t)
+	users := jsonboxgo.NewCollection[User](client, "users")
+	created, err := users.Create(User{Name: "taro", Age: 20})
+	if err != nil {
+		t.Fatalf("  Failed: Create() -> %v\n", err)
+	}
+	if _, err := users.Update(created.Id, User{Name: "taro", Age: 21}); err != nil {
+		t.Fatalf("  Failed: Update() -> %v\n", err)
+	}
+	updated, err := users.Get(created.Id)
+	if err != nil {
+		t.Fatalf("  Failed: Get() -> %v\n", err)
+	}
+	expected := User{Meta: jsonboxgo.Meta{Id: created.Id, CreatedOn: created.CreatedOn, UpdatedOn: "2020-04-26T16:26:13.001Z"}, Name: "taro", Age: 21}
+	if updated != expected {
+		t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", updated, updated, expected, expected)
+	}
+
+	if err := users.Delete(created.Id); err != nil {
+		t.Fatalf("  Failed: Delete() -> %v\n", err)
+	}
+	_, err = users.Get(created.Id)
+	if !errors.Is(err, jsonboxgo.ErrNotFound) {
+		t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", err, err, jsonboxgo.ErrNotFound, jsonboxgo.ErrNotFound)
+	}
+}
+
+func TestDeleteByQuery(t *testing.T) {
+	server, client := newTestServer(t)
+	for _, age := range []int{20, 30, 40} {
+		_ = client.Create("users", User{Name: "user", Age: age})
+	}
+	_ = client.Create("admins", User{Name: "admin", Age: 50})
+
+	result, err := client.DeleteByQuery("users", jsonboxgo.NewQueryBuilder().AndGreaterThanInt("age", 25))
+	if err != nil {
+		t.Fatalf("  Failed: DeleteByQuery() -> %v\n", err)
+	}
+	actual, expected := result.Count, 2
+	if actual != expected {
+		t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+	}
+	actual, expected = len(server.Records(testBoxId, "")), 2
+	if actual != expected {
+		t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+	}
+}
+
+func TestIterate(t *testing.T) {
+	_, client := newTestServer(t)
+	for i := 0; i < 25; i++ {
+		_ = client.Create("users", User{Name: "user", Age: i})
+	}
+
+	// test cases
+	testCases := map[string]struct {
+		InputQuery   jsonboxgo.Querier
+		InputOptions []jsonboxgo.IterateOption
+	}{
+		"Offset pagination.": {
+			InputQuery: jsonboxgo.NewQueryBuilder().SortAsc("age"),
+		},
+		"Keyset pagination.": {
+			InputQuery:   jsonboxgo.NewQueryBuilder(),
+			InputOptions: []jsonboxgo.IterateOption{jsonboxgo.KeysetPagination()},
+		},
+	}
+
+	// run
+	for testCase, param := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			it := client.Iterate("users", param.InputQuery, 10, param.InputOptions...)
+			ages := make([]int, 0)
+			for it.Next() {
+				var user User
+				_ = json.Unmarshal(it.Record(), &user)
+				ages = append(ages, user.Age)
+			}
+			if err := it.Err(); err != nil {
+				t.Fatalf("  Failed: Err() -> %v\n", err)
+			}
+			for i, age := range ages {
+				if age != i {
+					t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", age, age, i, i)
+				}
+			}
+			actual, expected := len(ages), 25
+			if actual != expected {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+			}
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	server, client := newTestServer(t)
+	invalidBoxClient, _ := server.NewClient("box")
+
+	// test cases
+	testCases := map[string]struct {
+		Input    func() error
+		Expected error
+	}{
+		"Record not found.": {
+			Input: func() error {
+				_, err := client.ReadWithError("users", "0123456789abcdef01234567")
+				return err
+			},
+			Expected: jsonboxgo.ErrNotFound,
+		},
+		"Invalid record id.": {
+			Input: func() error {
+				_, err := client.ReadWithError("users", "xxxx")
+				return err
+			},
+			Expected: jsonboxgo.ErrInvalidID,
+		},
+		"Invalid box id.": {
+			Input: func() error {
+				_, err := invalidBoxClient.ReadAllWithError("users")
+				return err
+			},
+			Expected: &jsonboxgo.APIError{StatusCode: 400},
+		},
+	}
+
+	// run
+	for testCase, param := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			actual := param.Input()
+			expected := param.Expected
+			var apiError *jsonboxgo.APIError
+			if expectedApiError, ok := expected.(*jsonboxgo.APIError); ok {
+				if !errors.As(actual, &apiError) || apiError.StatusCode != expectedApiError.StatusCode {
+					t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+				}
+				return
+			}
+			if !errors.Is(actual, expected) {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+			}
+		})
+	}
+}
+
+func TestCreateRetry(t *testing.T) {
+	// test cases
+	testCases := map[string]struct {
+		InputIdempotencyKeyHeader string
+		ExpectedRecordCount       int
+		ExpectedError             bool
+	}{
+		"Create is not retried by default.": {
+			ExpectedRecordCount: 1,
+			ExpectedError:       true,
+		},
+		"Idempotency key is not deduplicated by the server.": {
+			InputIdempotencyKeyHeader: "Idempotency-Key",
+			ExpectedRecordCount:       2,
+		},
+	}
+
+	// run
+	for testCase, param := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			server := NewServer()
+			t.Cleanup(server.Close)
+			// the first create is stored, but its response is lost
+			transport := server.Client().Transport
+			failed := false
+			httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				resp, err := transport.RoundTrip(req)
+				if err != nil || req.Method != http.MethodPost || failed {
+					return resp, err
+				}
+				failed = true
+				_ = resp.Body.Close()
+				return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: io.NopCloser(strings.NewReader(`{"message":"Service Unavailable"}`)), Header: make(http.Header), Request: req}, nil
+			})}
+			policy := jsonboxgo.RetryPolicy{
+				MaxAttempts:          2,
+				BaseDelay:            time.Millisecond,
+				RetryableStatusCodes: []int{http.StatusServiceUnavailable},
+				IdempotencyKeyHeader: param.InputIdempotencyKeyHeader,
+			}
+			client, err := server.NewClient(testBoxId, jsonboxgo.WithHTTPClient(httpClient), jsonboxgo.WithRetryPolicy(policy))
+			if err != nil {
+				t.Fatalf("  Failed: NewClient() -> %v\n", err)
+			}
+			_, err = client.CreateWithError("users", User{Name: "taro"})
+			if (err != nil) != param.ExpectedError {
+				t.Errorf("  Failed: err -> %v(%T), expectedError -> %v\n", err, err, param.ExpectedError)
+			}
+			actual := len(server.Records(testBoxId, "users"))
+			expected := param.ExpectedRecordCount
+			if actual != expected {
+				t.Errorf("  Failed: actual -> %v(%T), expected -> %v(%T)\n", actual, actual, expected, expected)
+			}
+		})
+	}
+}
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}